@@ -1,14 +1,13 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
-	"io/ioutil"
 	"os"
-	"path/filepath"
-	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/tribal/tribal-cli/internal/config"
+	"github.com/tribal/tribal-cli/internal/graph"
+	"github.com/tribal/tribal-cli/internal/history"
 )
 
 var commitCmd = &cobra.Command{
@@ -40,102 +39,52 @@ func commitGraph(message string) error {
 		return fmt.Errorf("not a tribal repository. Run 'tribal init' first")
 	}
 
-	configPath := filepath.Join(".tribal", "config.json")
-	configData, err := ioutil.ReadFile(configPath)
+	cfg, err := config.Load()
 	if err != nil {
-		return fmt.Errorf("failed to read config: %w", err)
+		return fmt.Errorf("failed to load config: %w", err)
 	}
-
-	var config map[string]interface{}
-	if err := json.Unmarshal(configData, &config); err != nil {
-		return fmt.Errorf("failed to parse config: %w", err)
-	}
-
-	stagedGraph, exists := config["staged_graph"].(string)
-	if !exists {
+	if cfg.StagedGraph == "" {
 		return fmt.Errorf("no staged changes. Use 'tribal add -A' first")
 	}
-
-	stagedGraphFile, exists := config["staged_graph_file"].(string)
-	if !exists {
+	if cfg.StagedGraphFile == "" {
 		return fmt.Errorf("no staged graph file found")
 	}
 
 	// Read staged graph
-	stagedData, err := ioutil.ReadFile(stagedGraphFile)
+	staged, err := graph.Load(cfg.StagedGraphFile)
 	if err != nil {
 		return fmt.Errorf("failed to read staged graph: %w", err)
 	}
 
-	var stagedGraphData map[string]interface{}
-	if err := json.Unmarshal(stagedData, &stagedGraphData); err != nil {
-		return fmt.Errorf("failed to parse staged graph: %w", err)
-	}
-
-	// Create commit object
-	commit := map[string]interface{}{
-		"id":        generateCommitID(),
-		"message":   message,
-		"timestamp": time.Now().Format(time.RFC3339),
-		"author":    "user", // TODO: get from git config
-		"graph":     stagedGraphData,
-	}
-
-	// Create commits directory if it doesn't exist
-	commitsDir := filepath.Join(".tribal", "commits")
-	if err := os.MkdirAll(commitsDir, 0755); err != nil {
-		return fmt.Errorf("failed to create commits directory: %w", err)
-	}
-
-	// Save commit
-	commitID := commit["id"].(string)
-	commitFile := filepath.Join(commitsDir, commitID+".json")
-	commitData, err := json.MarshalIndent(commit, "", "  ")
+	// Save the commit, clear staging and regenerate docs exported on commit
+	result, err := history.Commit(staged, message, "user") // TODO: get author from git config
 	if err != nil {
-		return fmt.Errorf("failed to serialize commit: %w", err)
-	}
-
-	if err := ioutil.WriteFile(commitFile, commitData, 0644); err != nil {
-		return fmt.Errorf("failed to save commit: %w", err)
-	}
-
-	// Update config with latest commit
-	config["latest_commit"] = commitID
-	config["latest_commit_file"] = commitFile
-
-	// Clear staging
-	delete(config, "staged_graph")
-	delete(config, "staged_graph_file")
-
-	configData, err = json.MarshalIndent(config, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to serialize config: %w", err)
-	}
-
-	if err := ioutil.WriteFile(configPath, configData, 0644); err != nil {
-		return fmt.Errorf("failed to update config: %w", err)
+		return err
 	}
+	commit := result.Commit
 
 	// Show commit summary
-	fmt.Printf("Committed graph: %s\n", stagedGraph)
-	fmt.Printf("Commit ID: %s\n", commitID)
+	fmt.Printf("Committed graph: %s\n", cfg.StagedGraph)
+	fmt.Printf("Commit ID: %s\n", commit.ID)
 	fmt.Printf("Message: %s\n", message)
-	fmt.Printf("Timestamp: %s\n", commit["timestamp"])
+	fmt.Printf("Timestamp: %s\n", commit.Timestamp)
+	if sha := commit.GitCommit(); sha != "" {
+		fmt.Printf("Git commit: %s\n", sha)
+	}
 
 	// Show simple diff (node/edge counts)
-	nodes, _ := stagedGraphData["nodes"].([]interface{})
-	edges, _ := stagedGraphData["edges"].([]interface{})
 	fmt.Printf("\nGraph Summary:\n")
-	fmt.Printf("  Nodes: %d\n", len(nodes))
-	fmt.Printf("  Edges: %d\n", len(edges))
+	fmt.Printf("  Nodes: %d\n", len(staged.Nodes))
+	fmt.Printf("  Edges: %d\n", len(staged.Edges))
+
+	fmt.Printf("\nCommit saved to: %s\n", result.Path)
 
-	fmt.Printf("\nCommit saved to: %s\n", commitFile)
+	if result.DocsErr != nil {
+		fmt.Printf("Warning: failed to regenerate markdown docs: %v\n", result.DocsErr)
+	} else if result.DocsDir != "" {
+		fmt.Printf("Regenerated %d markdown files in %s\n", len(result.Docs), result.DocsDir)
+	}
 	fmt.Println("\nReview this commit before pushing. Use 'tribal push' when ready.")
 
 	return nil
 }
-
-func generateCommitID() string {
-	// Simple timestamp-based ID for now
-	return fmt.Sprintf("commit_%d", time.Now().Unix())
-}
\ No newline at end of file
@@ -0,0 +1,276 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/tribal/tribal-cli/internal/config"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Get and set configuration",
+	Long: `Read and write tribal settings.
+
+Settings are resolved from, in order of precedence:
+
+  1. environment variables: TRIBAL_REGISTRY_URL, TRIBAL_TOKEN
+  2. the repository config, .tribal/config.json (--local)
+  3. the user config, $XDG_CONFIG_HOME/tribal/config.json (--global)
+  4. the system config, /etc/tribal/config.json
+  5. built-in defaults
+
+registry_url, docs_template and credential_store can be set globally;
+markdown_export_dir and git_auto_commit only per repository. 'set' and
+'unset' write the repository config unless --global is given.
+
+token, username and user_id are not kept in config files. 'tribal login'
+writes them to the credential store for the registry, and TRIBAL_TOKEN
+overrides the stored token.`,
+}
+
+var configGetCmd = &cobra.Command{
+	Use:   "get <key>",
+	Short: "Print the value of a setting",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		scope, err := configScope(cmd, "")
+		if err == nil {
+			err = getConfig(args[0], scope)
+		}
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Set a setting",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		scope, err := configScope(cmd, config.SourceLocal)
+		if err == nil {
+			err = setConfig(args[0], &args[1], scope)
+		}
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var configUnsetCmd = &cobra.Command{
+	Use:   "unset <key>",
+	Short: "Remove a setting",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		scope, err := configScope(cmd, config.SourceLocal)
+		if err == nil {
+			err = setConfig(args[0], nil, scope)
+		}
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var configListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List settings and where they come from",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		showSecrets, _ := cmd.Flags().GetBool("show-secrets")
+		scope, err := configScope(cmd, "")
+		if err == nil {
+			err = listConfig(scope, showSecrets)
+		}
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	for _, c := range []*cobra.Command{configGetCmd, configSetCmd, configUnsetCmd, configListCmd} {
+		c.Flags().Bool("global", false, "Use the user config")
+		c.Flags().Bool("local", false, "Use the repository config")
+		configCmd.AddCommand(c)
+	}
+	configListCmd.Flags().Bool("show-secrets", false, "Print tokens in full")
+	rootCmd.AddCommand(configCmd)
+}
+
+// configScope returns the layer selected with --global or --local, or def
+// when neither is given.
+func configScope(cmd *cobra.Command, def config.Source) (config.Source, error) {
+	global, _ := cmd.Flags().GetBool("global")
+	local, _ := cmd.Flags().GetBool("local")
+	switch {
+	case global && local:
+		return "", fmt.Errorf("--global and --local cannot be used together")
+	case global:
+		return config.SourceUser, nil
+	case local:
+		return config.SourceLocal, nil
+	}
+	return def, nil
+}
+
+// configFile returns the path of the config file for a scope.
+func configFile(scope config.Source) (string, error) {
+	if scope == config.SourceUser {
+		return config.UserConfigPath()
+	}
+	if _, err := os.Stat(config.GetConfigPath()); os.IsNotExist(err) {
+		return "", fmt.Errorf("not a tribal repository. Run 'tribal init' first, or use --global")
+	}
+	return config.GetConfigPath(), nil
+}
+
+func lookupSetting(key string) (config.Setting, error) {
+	s, ok := config.Lookup(key)
+	if !ok {
+		var keys []string
+		for _, s := range config.Settings {
+			keys = append(keys, s.Key)
+		}
+		return s, fmt.Errorf("unknown setting %q. Known settings: %s", key, strings.Join(keys, ", "))
+	}
+	return s, nil
+}
+
+func getConfig(key string, scope config.Source) error {
+	s, err := lookupSetting(key)
+	if err != nil {
+		return err
+	}
+
+	if scope == "" {
+		values, err := config.Resolve()
+		if err != nil {
+			return err
+		}
+		for _, v := range values {
+			if v.Setting.Key == key {
+				fmt.Println(v.Value)
+				return nil
+			}
+		}
+		return fmt.Errorf("%s is not set", key)
+	}
+
+	path, err := configFile(scope)
+	if err != nil {
+		return err
+	}
+	values, err := config.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	v, ok := values[s.Key]
+	if !ok || v == "" {
+		return fmt.Errorf("%s is not set in %s", key, path)
+	}
+	fmt.Println(v)
+	return nil
+}
+
+// setConfig stores value in the scope's config file, or removes the key
+// when value is nil.
+func setConfig(key string, value *string, scope config.Source) error {
+	s, err := lookupSetting(key)
+	if err != nil {
+		return err
+	}
+	if s.Credential {
+		return fmt.Errorf("%s is kept in the credential store. Use 'tribal login' or 'tribal logout' to change it", key)
+	}
+	if scope == config.SourceUser && !s.Global {
+		return fmt.Errorf("%s can only be set per repository", key)
+	}
+
+	path, err := configFile(scope)
+	if err != nil {
+		return err
+	}
+	values, err := config.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	if value == nil {
+		if _, ok := values[s.Key]; !ok {
+			return fmt.Errorf("%s is not set in %s", key, path)
+		}
+		delete(values, s.Key)
+	} else {
+		v, err := s.Parse(*value)
+		if err != nil {
+			return err
+		}
+		values[s.Key] = v
+	}
+
+	// The user config held credentials in earlier versions
+	perm := os.FileMode(0644)
+	if scope == config.SourceUser {
+		perm = 0600
+	}
+	return config.WriteFile(path, values, perm)
+}
+
+func listConfig(scope config.Source, showSecrets bool) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	if scope == "" {
+		values, err := config.Resolve()
+		if err != nil {
+			return err
+		}
+		for _, v := range values {
+			fmt.Fprintf(w, "%s=%s\t(%s)\n", v.Setting.Key, displayValue(v.Setting, v.Value, showSecrets), v.Source)
+		}
+		return nil
+	}
+
+	path, err := configFile(scope)
+	if err != nil {
+		return err
+	}
+	values, err := config.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var keys []string
+	for _, s := range config.Settings {
+		if v, ok := values[s.Key]; ok && v != "" {
+			keys = append(keys, s.Key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		s, _ := config.Lookup(key)
+		fmt.Fprintf(w, "%s=%s\n", key, displayValue(s, fmt.Sprint(values[key]), showSecrets))
+	}
+	return nil
+}
+
+// displayValue masks secrets down to their last four characters.
+func displayValue(s config.Setting, value string, showSecrets bool) string {
+	if !s.Secret || showSecrets {
+		return value
+	}
+	if len(value) <= 8 {
+		return "****"
+	}
+	return "****" + value[len(value)-4:]
+}
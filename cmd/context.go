@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/tribal/tribal-cli/internal/briefing"
+	"github.com/tribal/tribal-cli/internal/graph"
+	"github.com/tribal/tribal-cli/internal/search"
+)
+
+var contextCmd = &cobra.Command{
+	Use:   "context <task description>",
+	Short: "Print the graph knowledge relevant to a task",
+	Long: `Search the local graphs for a task description, expand the best-matching
+nodes to their neighborhood and print a briefing with the relevant node
+markup, code anchors and relationships, ready to paste into an agent's prompt.
+
+Nodes matching the task come first, then their neighbors by distance, marked
+with the number of hops from a match. Nodes are added until the briefing
+reaches --max-tokens (estimated at four characters per token); markup is
+truncated where that lets a node fit, and nodes left out are listed at the
+end.`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		graphTitle, _ := cmd.Flags().GetString("graph")
+		format, _ := cmd.Flags().GetString("format")
+		depth, _ := cmd.Flags().GetInt("depth")
+		seeds, _ := cmd.Flags().GetInt("seeds")
+		maxTokens, _ := cmd.Flags().GetInt("max-tokens")
+
+		opts := briefing.Options{Graph: graphTitle, Seeds: seeds, Depth: depth, MaxTokens: maxTokens, Format: format}
+		if err := printContext(strings.Join(args, " "), opts); err != nil {
+			fmt.Printf("Error building context: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	contextCmd.Flags().StringP("graph", "g", "", "Only use the graph with this title")
+	contextCmd.Flags().String("format", briefing.FormatMarkdown, "Output format (markdown or json)")
+	contextCmd.Flags().Int("depth", 1, "Number of hops around each matching node to include")
+	contextCmd.Flags().Int("seeds", 5, "Number of best-matching nodes to expand from")
+	contextCmd.Flags().Int("max-tokens", 2000, "Approximate size limit of the briefing in tokens")
+	rootCmd.AddCommand(contextCmd)
+}
+
+func printContext(task string, opts briefing.Options) error {
+	if opts.Format != briefing.FormatMarkdown && opts.Format != briefing.FormatJSON {
+		return fmt.Errorf("unknown format %q. Use markdown or json", opts.Format)
+	}
+	if opts.Depth < 0 || opts.Seeds < 1 {
+		return fmt.Errorf("--depth must not be negative and --seeds must be at least 1")
+	}
+	if opts.MaxTokens < 200 {
+		return fmt.Errorf("--max-tokens must be at least 200")
+	}
+
+	if _, err := os.Stat(".tribal"); os.IsNotExist(err) {
+		return fmt.Errorf("not a tribal repository. Run 'tribal init' first")
+	}
+	files, err := graph.LoadAll()
+	if err != nil {
+		return err
+	}
+	if err := search.CheckGraph(files, opts.Graph); err != nil {
+		return err
+	}
+
+	b := briefing.Build(files, task, opts)
+	fmt.Print(b.Render(opts.Format))
+	if opts.Format == briefing.FormatJSON {
+		fmt.Println()
+	}
+	return nil
+}
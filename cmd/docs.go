@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/tribal/tribal-cli/internal/config"
+	"github.com/tribal/tribal-cli/internal/docs"
+	"github.com/tribal/tribal-cli/internal/managed"
+	"github.com/tribal/tribal-cli/internal/plan"
+)
+
+// tribalDocPath is the repository guide rendered by 'tribal init'.
+const tribalDocPath = "TRIBAL.md"
+
+var docsCmd = &cobra.Command{
+	Use:   "docs",
+	Short: "Maintain TRIBAL.md",
+}
+
+var docsRegenerateCmd = &cobra.Command{
+	Use:   "regenerate",
+	Short: "Refresh TRIBAL.md with the current graph index",
+	Long: `Render TRIBAL.md again from its template, with an up-to-date index of the
+local graphs and their descriptions. Only the marked tribal section of the
+file is replaced.
+
+Templates are Go text/template files rendered with:
+
+  .Project       name of the repository directory
+  .RegistryURL   registry URL from the config
+  .Graphs        local graphs: .Title, .Description, .File, .Nodes, .Edges
+  .Conventions   contents of .tribal/conventions.md
+
+and the functions join, lower, upper, trim and oneline.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		template, _ := cmd.Flags().GetString("template")
+		if _, err := os.Stat(userPath(template)); err == nil {
+			template = userPath(template)
+		}
+		force, _ := cmd.Flags().GetBool("force")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		if err := regenerateDocs(template, force, dryRun); err != nil {
+			fmt.Printf("Error regenerating docs: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var docsTemplatesCmd = &cobra.Command{
+	Use:   "templates",
+	Short: "List the available TRIBAL.md templates",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		for _, name := range docs.Names() {
+			fmt.Println(name)
+		}
+	},
+}
+
+func init() {
+	docsRegenerateCmd.Flags().String("template", "", "Use this template (name or path) and remember it")
+	docsRegenerateCmd.Flags().Bool("force", false, "Replace TRIBAL.md even if it was written without tribal markers")
+	docsRegenerateCmd.Flags().Bool("dry-run", false, "Print whether TRIBAL.md would change without writing it")
+	docsCmd.AddCommand(docsRegenerateCmd)
+	docsCmd.AddCommand(docsTemplatesCmd)
+	rootCmd.AddCommand(docsCmd)
+}
+
+func regenerateDocs(template string, force, dryRun bool) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	if template != "" {
+		cfg.DocsTemplate = template
+	}
+
+	change, err := planTribalDoc(cfg, force)
+	if err != nil {
+		return err
+	}
+	fmt.Println(change.Describe(dryRun))
+	if dryRun {
+		return nil
+	}
+	if err := change.Apply(); err != nil {
+		return err
+	}
+	if template != "" {
+		if err := cfg.Save(); err != nil {
+			return fmt.Errorf("failed to save config: %w", err)
+		}
+	}
+	return nil
+}
+
+// planTribalDoc renders TRIBAL.md from the configured template into the
+// marked section of the file. A TRIBAL.md written by hand without markers
+// is left alone unless force is set.
+func planTribalDoc(cfg *config.Config, force bool) (plan.Change, error) {
+	text, err := docs.Load(cfg.DocsTemplate)
+	if err != nil {
+		return plan.Change{}, err
+	}
+	data, err := docs.Collect(cfg.RegistryURL)
+	if err != nil {
+		return plan.Change{}, err
+	}
+	rendered, err := docs.Render(text, data)
+	if err != nil {
+		return plan.Change{}, err
+	}
+
+	current, err := readOptional(tribalDocPath)
+	if err != nil {
+		return plan.Change{}, err
+	}
+	switch {
+	case force || current == "" || isLegacyTribalDoc(current):
+		current = ""
+	case !managed.Has(current, managed.MarkdownMarkers):
+		return plan.Skipped(tribalDocPath, "customized without tribal markers; use --force to replace it"), nil
+	}
+	return plan.File(tribalDocPath, []byte(managed.Apply(current, rendered, managed.MarkdownMarkers, true)), "")
+}
+
+// isLegacyTribalDoc reports whether content is the TRIBAL.md written by
+// earlier versions of init, which matches the built-in template rendered
+// without graphs.
+func isLegacyTribalDoc(content string) bool {
+	text, err := docs.Load(docs.DefaultTemplate)
+	if err != nil {
+		return false
+	}
+	legacy, err := docs.Render(text, docs.Data{})
+	return err == nil && content == legacy
+}
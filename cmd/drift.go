@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/tribal/tribal-cli/internal/anchor"
+	"github.com/tribal/tribal-cli/internal/graph"
+)
+
+// maxDriftCommits is the number of git commits listed per stale anchor.
+const maxDriftCommits = 3
+
+var driftCmd = &cobra.Command{
+	Use:   "drift",
+	Short: "Report nodes whose linked code has changed",
+	Long: `Compare every code anchor with the working tree and report the nodes whose
+linked files, lines or Go symbols have changed, moved or been deleted since
+they were linked. When the anchor was recorded inside a git repository, the
+commits that touched the file since then are listed too.
+
+Exits with a non-zero status when any anchor is stale, for use in CI. After
+reviewing the affected nodes, run with --update to record the current code
+as the new baseline; moved anchors are updated to their new location.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		title, _ := cmd.Flags().GetString("graph")
+		update, _ := cmd.Flags().GetBool("update")
+
+		stale, err := checkDrift(title, update)
+		if err != nil {
+			fmt.Printf("Error checking drift: %v\n", err)
+			os.Exit(1)
+		}
+		if stale > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	driftCmd.Flags().StringP("graph", "g", "", "Only check this graph (default: every graph)")
+	driftCmd.Flags().Bool("update", false, "Record the current code as the baseline for changed and moved anchors")
+	rootCmd.AddCommand(driftCmd)
+}
+
+// checkDrift reports drift across graphs and returns the number of anchors
+// still stale afterwards.
+func checkDrift(title string, update bool) (int, error) {
+	var files []graph.File
+	if title != "" {
+		f, err := graph.Open(title)
+		if err != nil {
+			return 0, err
+		}
+		files = []graph.File{*f}
+	} else {
+		var err error
+		if files, err = graph.LoadAll(); err != nil {
+			return 0, err
+		}
+	}
+
+	counts := make(map[anchor.Status]int)
+	total, stale := 0, 0
+	for _, f := range files {
+		changed := false
+		printedGraph := false
+
+		for i := range f.Graph.Nodes {
+			node := &f.Graph.Nodes[i]
+			printedNode := false
+
+			for j, a := range node.Anchors {
+				d := anchor.Detect(workTree(), a)
+				total++
+				counts[d.Status]++
+				if d.Status == anchor.StatusOK {
+					if !anchor.Equal(d.Current, a) && update {
+						node.Anchors[j] = d.Current
+						changed = true
+					}
+					continue
+				}
+
+				if !printedGraph {
+					fmt.Printf("%s\n", f.Graph.Title)
+					printedGraph = true
+				}
+				if !printedNode {
+					fmt.Printf("  %s (%s)\n", node.Label, node.ID)
+					printedNode = true
+				}
+				fmt.Printf("    %-10s %s: %s\n", d.Status, anchor.String(a), d.Detail)
+				if len(d.Commits) > 0 {
+					var shown []string
+					for k, c := range d.Commits {
+						if k == maxDriftCommits {
+							shown = append(shown, fmt.Sprintf("and %d more", len(d.Commits)-k))
+							break
+						}
+						shown = append(shown, c.SHA+" "+c.Subject)
+					}
+					fmt.Printf("    %-10s %d commit(s) since linked: %s\n", "", len(d.Commits), strings.Join(shown, "; "))
+				}
+
+				if update && (d.Status == anchor.StatusChanged || d.Status == anchor.StatusMoved || d.Status == anchor.StatusUnrecorded) {
+					current := d.Current
+					if err := anchor.Record(workTree(), &current); err != nil {
+						fmt.Printf("    %-10s could not update: %v\n", "", err)
+						stale++
+						continue
+					}
+					node.Anchors[j] = current
+					changed = true
+					fmt.Printf("    %-10s updated to %s\n", "", anchor.String(current))
+					continue
+				}
+				if d.Stale() {
+					stale++
+				}
+			}
+		}
+
+		if changed {
+			if err := f.Graph.Save(f.Path); err != nil {
+				return stale, err
+			}
+		}
+	}
+
+	if total == 0 {
+		fmt.Println("No code anchors to check. Link nodes with 'tribal node link'.")
+		return 0, nil
+	}
+
+	if counts[anchor.StatusOK] < total {
+		fmt.Println()
+	}
+	fmt.Printf("Checked %d anchors: %d ok, %d changed, %d moved, %d deleted", total,
+		counts[anchor.StatusOK], counts[anchor.StatusChanged], counts[anchor.StatusMoved], counts[anchor.StatusDeleted])
+	if n := counts[anchor.StatusUnrecorded]; n > 0 {
+		fmt.Printf(", %d without a baseline", n)
+	}
+	fmt.Println()
+	if stale > 0 {
+		fmt.Printf("%d anchor(s) are stale. Review the nodes above, then run 'tribal drift --update'\n", stale)
+		fmt.Println("or remove anchors to deleted code with 'tribal node unlink'.")
+	}
+	return stale, nil
+}
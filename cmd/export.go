@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/tribal/tribal-cli/internal/config"
+	"github.com/tribal/tribal-cli/internal/export"
+	"github.com/tribal/tribal-cli/internal/graph"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export a graph as a diagram",
+	Long: `Render the current graph, a graph given with -g, or the graph stored in a
+commit as a diagram. Edge direction and labels are exported in every format;
+node positions and sizes are exported where the format supports them.
+
+The markdown format produces agent-readable documentation: a table of
+contents, an embedded Mermaid diagram and a section per node with its markup
+and links to connected nodes. Use --all with --out <dir> to export every graph
+into a directory, and --on-commit to regenerate that directory after each
+'tribal commit'.
+
+The html format produces a single offline page with a pannable diagram and
+the markup of each node. With --history the page includes every commit of
+the graph and a selector to switch between them.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		format, _ := cmd.Flags().GetString("format")
+		out, _ := cmd.Flags().GetString("out")
+		out = userPath(out)
+		graphTitle, _ := cmd.Flags().GetString("graph")
+		commitID, _ := cmd.Flags().GetString("commit")
+		all, _ := cmd.Flags().GetBool("all")
+		onCommit, _ := cmd.Flags().GetBool("on-commit")
+		history, _ := cmd.Flags().GetBool("history")
+
+		var err error
+		if all {
+			err = exportAllGraphs(format, out, onCommit)
+		} else if onCommit {
+			err = fmt.Errorf("--on-commit requires --all")
+		} else if history {
+			err = exportHistory(format, out, graphTitle)
+		} else {
+			err = exportGraph(format, out, graphTitle, commitID)
+		}
+		if err != nil {
+			fmt.Printf("Error exporting graph: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	exportCmd.Flags().StringP("format", "f", "dot", "Output format: "+strings.Join(export.Formats(), ", "))
+	exportCmd.Flags().StringP("out", "o", "", "Write to a file instead of stdout")
+	exportCmd.Flags().StringP("graph", "g", "", "Graph title to export (default: current graph)")
+	exportCmd.Flags().StringP("commit", "c", "", "Export the graph stored in this commit")
+	exportCmd.Flags().Bool("all", false, "Export every graph into the directory given by --out")
+	exportCmd.Flags().Bool("on-commit", false, "Regenerate this markdown export after every commit")
+	exportCmd.Flags().Bool("history", false, "Include every commit of the graph (html format only)")
+	rootCmd.AddCommand(exportCmd)
+}
+
+func exportGraph(format, out, graphTitle, commitID string) error {
+	g, err := loadExportGraph(graphTitle, commitID)
+	if err != nil {
+		return err
+	}
+
+	data, err := export.Render(format, g)
+	if err != nil {
+		return err
+	}
+
+	return writeExport(g.Title, data, out)
+}
+
+func exportHistory(format, out, graphTitle string) error {
+	if format != "html" {
+		return fmt.Errorf("--history is only supported for the html format")
+	}
+
+	f, err := graph.Open(graphTitle)
+	if err != nil {
+		return err
+	}
+
+	commits, err := graph.ListCommits()
+	if err != nil {
+		return err
+	}
+
+	snapshots := append([]export.Snapshot{{Label: "Working copy", Graph: f.Graph}},
+		export.CommitSnapshots(f.Graph.Title, commits)...)
+
+	data, err := export.HTMLHistory(snapshots)
+	if err != nil {
+		return err
+	}
+
+	return writeExport(f.Graph.Title, data, out)
+}
+
+// writeExport prints data, or writes it to out when set.
+func writeExport(title string, data []byte, out string) error {
+	if out == "" {
+		fmt.Print(string(data))
+		return nil
+	}
+
+	if err := ioutil.WriteFile(out, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", out, err)
+	}
+	fmt.Printf("Exported %s to %s\n", title, out)
+
+	return nil
+}
+
+func exportAllGraphs(format, dir string, onCommit bool) error {
+	if dir == "" {
+		return fmt.Errorf("--all requires --out <directory>")
+	}
+	if onCommit && format != "markdown" {
+		return fmt.Errorf("--on-commit is only supported for the markdown format")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	written, err := writeAllGraphs(format, dir)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Exported %d files to %s\n", len(written), dir)
+
+	if onCommit {
+		cfg.MarkdownExportDir = dir
+		if err := cfg.Save(); err != nil {
+			return err
+		}
+		fmt.Printf("%s will be regenerated after every commit\n", dir)
+	}
+
+	return nil
+}
+
+func writeAllGraphs(format, dir string) ([]string, error) {
+	files, err := graph.LoadAll()
+	if err != nil {
+		return nil, err
+	}
+	return export.WriteAll(format, files, dir)
+}
+
+// loadExportGraph returns the graph stored in a commit when commitID is set,
+// and otherwise the named or current graph.
+func loadExportGraph(graphTitle, commitID string) (*graph.Graph, error) {
+	if commitID == "" {
+		f, err := graph.Open(graphTitle)
+		if err != nil {
+			return nil, err
+		}
+		return f.Graph, nil
+	}
+
+	if graphTitle != "" {
+		return nil, fmt.Errorf("--graph and --commit cannot be used together")
+	}
+	if _, err := os.Stat(".tribal"); os.IsNotExist(err) {
+		return nil, fmt.Errorf("not a tribal repository. Run 'tribal init' first")
+	}
+
+	c, err := graph.LoadCommit(commitID)
+	if err != nil {
+		return nil, err
+	}
+	return c.Graph, nil
+}
@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"github.com/tribal/tribal-cli/internal/client"
+	"github.com/tribal/tribal-cli/internal/config"
+	"github.com/tribal/tribal-cli/internal/graph"
+	"github.com/tribal/tribal-cli/internal/remote"
+)
+
+var fetchCmd = &cobra.Command{
+	Use:   "fetch [remote]",
+	Short: "Download graphs from a remote registry",
+	Long: `Download the registry's version of published graphs into
+.tribal/remotes/<remote>/ and report how each differs from the local graph.
+Local graphs are not changed.
+
+Without -g every tracked graph is fetched: every graph pushed to or
+fetched from the remote. Without a remote each graph is fetched from its
+upstream, then the default remote. --id fetches a graph that was published
+from elsewhere and starts tracking it.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		titles, _ := cmd.Flags().GetStringSlice("graph")
+		id, _ := cmd.Flags().GetString("id")
+		name := ""
+		if len(args) > 0 {
+			name = args[0]
+		}
+		if err := fetchGraphs(name, titles, id); err != nil {
+			fmt.Printf("Error fetching graphs: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	fetchCmd.Flags().StringSliceP("graph", "g", nil, "Graphs to fetch (default: every tracked graph)")
+	fetchCmd.Flags().String("id", "", "Registry ID of a graph to fetch and track")
+	rootCmd.AddCommand(fetchCmd)
+}
+
+func fetchGraphs(name string, titles []string, id string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	if id != "" && len(titles) > 0 {
+		return fmt.Errorf("--id and --graph cannot be used together")
+	}
+	if name != "" {
+		if _, _, err := cfg.ResolveRemote(name, ""); err != nil {
+			return err
+		}
+	}
+
+	clients := make(map[string]*client.Client)
+	clientFor := func(name string, r *config.Remote) (*client.Client, error) {
+		if c, ok := clients[name]; ok {
+			return c, nil
+		}
+		c, err := remote.Client(cfg, r)
+		clients[name] = c
+		return c, err
+	}
+
+	if id != "" {
+		name, r, err := cfg.ResolveRemote(name, "")
+		if err != nil {
+			return err
+		}
+		c, err := clientFor(name, r)
+		if err != nil {
+			return err
+		}
+		g, err := remote.Fetch(c, id)
+		if err != nil {
+			return fmt.Errorf("failed to fetch %s from %s: %w", id, name, err)
+		}
+		cfg.TrackingFor(g.Title).IDs[name] = id
+		if err := reportFetched(name, g); err != nil {
+			return err
+		}
+		return cfg.Save()
+	}
+
+	explicit := len(titles) > 0
+	if !explicit {
+		for title := range cfg.Tracking {
+			titles = append(titles, title)
+		}
+		sort.Strings(titles)
+	}
+
+	fetched := 0
+	for _, title := range titles {
+		n, r, err := cfg.ResolveRemote(name, title)
+		if err != nil {
+			return err
+		}
+		t := cfg.Tracking[title]
+		if t == nil || t.IDs[n] == "" {
+			if explicit {
+				return fmt.Errorf("graph %s is not published on %s. Push it with 'tribal push %s -g \"%s\"'", title, n, n, title)
+			}
+			continue
+		}
+		c, err := clientFor(n, r)
+		if err != nil {
+			return err
+		}
+		g, err := remote.Fetch(c, t.IDs[n])
+		if err != nil {
+			return fmt.Errorf("failed to fetch %s from %s: %w", title, n, err)
+		}
+		if err := reportFetched(n, g); err != nil {
+			return err
+		}
+		fetched++
+	}
+
+	if fetched == 0 {
+		fmt.Println("Nothing to fetch: no tracked graphs are published on the remote. Use --id to fetch a graph by registry ID.")
+	}
+	return nil
+}
+
+// reportFetched stores a fetched graph and prints how it differs from the
+// local graph with the same title.
+func reportFetched(name string, g *graph.Graph) error {
+	path, err := remote.Save(name, g)
+	if err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(graph.PathFor(g.Title)); os.IsNotExist(err) {
+		fmt.Printf("%s/%s: not in the local graphs (%s)\n", name, g.Title, path)
+		return nil
+	}
+	local, err := graph.Load(graph.PathFor(g.Title))
+	if err != nil {
+		return err
+	}
+
+	changes := graph.Diff(local, g)
+	if changes.Empty() {
+		fmt.Printf("%s/%s: up to date\n", name, g.Title)
+		return nil
+	}
+	fmt.Printf("%s/%s: nodes +%d -%d ~%d, edges +%d -%d ~%d compared to local (%s)\n", name, g.Title,
+		len(changes.NodesAdded), len(changes.NodesRemoved), len(changes.NodesChanged),
+		len(changes.EdgesAdded), len(changes.EdgesRemoved), len(changes.EdgesChanged), path)
+	return nil
+}
@@ -0,0 +1,247 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/tribal/tribal-cli/internal/client"
+	"github.com/tribal/tribal-cli/internal/graph"
+)
+
+var graphCmd = &cobra.Command{
+	Use:   "graph",
+	Short: "Traverse and analyze the current graph",
+	Long: `Inspect the structure of the current graph (or the graph given with -g).
+Nodes may be referred to by ID or by label.`,
+}
+
+var graphNeighborsCmd = &cobra.Command{
+	Use:   "neighbors <node>",
+	Short: "List the nodes connected to a node",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runGraphCommand(cmd, func(g *graph.Graph) error {
+			return showNeighbors(g, args[0])
+		})
+	},
+}
+
+var graphPathCmd = &cobra.Command{
+	Use:   "path <from> <to>",
+	Short: "Show the shortest path between two nodes",
+	Long: `Show the shortest path between two nodes. Directed edges are only followed
+from source to target; undirected edges are followed both ways.`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		runGraphCommand(cmd, func(g *graph.Graph) error {
+			return showPath(g, args[0], args[1])
+		})
+	},
+}
+
+var graphCyclesCmd = &cobra.Command{
+	Use:   "cycles",
+	Short: "Detect cycles formed by directed edges",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		runGraphCommand(cmd, showCycles)
+	},
+}
+
+var graphComponentsCmd = &cobra.Command{
+	Use:   "components",
+	Short: "List connected components and isolated nodes",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		runGraphCommand(cmd, showComponents)
+	},
+}
+
+var graphTopoCmd = &cobra.Command{
+	Use:   "topo",
+	Short: "Print nodes in topological order of their directed edges",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		runGraphCommand(cmd, showTopologicalOrder)
+	},
+}
+
+var graphDegreeCmd = &cobra.Command{
+	Use:   "degree",
+	Short: "Show in, out and undirected edge counts per node",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		runGraphCommand(cmd, showDegrees)
+	},
+}
+
+func init() {
+	graphCmd.PersistentFlags().StringP("graph", "g", "", "Graph title (default: current graph)")
+	graphCmd.AddCommand(graphNeighborsCmd)
+	graphCmd.AddCommand(graphPathCmd)
+	graphCmd.AddCommand(graphCyclesCmd)
+	graphCmd.AddCommand(graphComponentsCmd)
+	graphCmd.AddCommand(graphTopoCmd)
+	graphCmd.AddCommand(graphDegreeCmd)
+	rootCmd.AddCommand(graphCmd)
+}
+
+// runGraphCommand opens the graph selected by the -g flag and runs fn on it,
+// exiting with an error message on failure.
+func runGraphCommand(cmd *cobra.Command, fn func(g *graph.Graph) error) {
+	title, _ := cmd.Flags().GetString("graph")
+
+	f, err := graph.Open(title)
+	if err == nil {
+		err = fn(f.Graph)
+	}
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func showNeighbors(g *graph.Graph, ref string) error {
+	node, err := g.Resolve(ref)
+	if err != nil {
+		return err
+	}
+
+	neighbors := g.Neighbors(node.ID)
+	fmt.Printf("Neighbors of %s (%s):\n\n", node.Label, node.ID)
+	if len(neighbors) == 0 {
+		fmt.Println("No neighbors.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "DIRECTION\tID\tLABEL\tEDGE")
+	for _, n := range neighbors {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", n.Direction, n.Node.ID, n.Node.Label, edgeName(n.Edge))
+	}
+	return w.Flush()
+}
+
+func showPath(g *graph.Graph, fromRef, toRef string) error {
+	from, err := g.Resolve(fromRef)
+	if err != nil {
+		return err
+	}
+	to, err := g.Resolve(toRef)
+	if err != nil {
+		return err
+	}
+
+	path, ok := g.ShortestPath(from.ID, to.ID)
+	if !ok {
+		return fmt.Errorf("no path from %s to %s", from.Label, to.Label)
+	}
+
+	fmt.Printf("Path from %s to %s (%d hops):\n\n", from.Label, to.Label, len(path))
+	fmt.Printf("  %s (%s)\n", from.Label, from.ID)
+	cur := from.ID
+	for _, e := range path {
+		next := e.Target
+		if e.Target == cur {
+			next = e.Source
+		}
+		arrow := "--"
+		if e.Directed {
+			arrow = "->"
+		}
+		fmt.Printf("    %s %s\n", arrow, edgeName(e))
+		fmt.Printf("  %s (%s)\n", g.NodeLabel(next), next)
+		cur = next
+	}
+
+	return nil
+}
+
+func showCycles(g *graph.Graph) error {
+	cycles := g.Cycles()
+	if len(cycles) == 0 {
+		fmt.Println("No cycles found.")
+		return nil
+	}
+
+	fmt.Printf("Found %d cycle(s):\n\n", len(cycles))
+	for i, cycle := range cycles {
+		labels := make([]string, len(cycle))
+		for j, id := range cycle {
+			labels[j] = g.NodeLabel(id)
+		}
+		fmt.Printf("%d. %s\n", i+1, strings.Join(labels, " -> "))
+	}
+
+	return nil
+}
+
+func showComponents(g *graph.Graph) error {
+	components := g.Components()
+	if len(components) == 0 {
+		fmt.Println("Graph has no nodes.")
+		return nil
+	}
+
+	var isolated []client.Node
+	fmt.Printf("Found %d connected component(s):\n\n", len(components))
+	for i, component := range components {
+		if len(component) == 1 {
+			isolated = append(isolated, component[0])
+			continue
+		}
+		labels := make([]string, len(component))
+		for j, n := range component {
+			labels[j] = n.Label
+		}
+		fmt.Printf("%d. %d nodes: %s\n", i+1, len(component), strings.Join(labels, ", "))
+	}
+
+	if len(isolated) > 0 {
+		fmt.Printf("\nIsolated nodes (%d):\n", len(isolated))
+		for _, n := range isolated {
+			fmt.Printf("  %s (%s)\n", n.Label, n.ID)
+		}
+	}
+
+	return nil
+}
+
+func showTopologicalOrder(g *graph.Graph) error {
+	order, err := g.TopologicalOrder()
+	if err != nil {
+		return err
+	}
+
+	for i, n := range order {
+		fmt.Printf("%d. %s (%s)\n", i+1, n.Label, n.ID)
+	}
+
+	return nil
+}
+
+func showDegrees(g *graph.Graph) error {
+	degrees := g.Degrees()
+	sort.SliceStable(degrees, func(i, j int) bool {
+		return degrees[i].Total() > degrees[j].Total()
+	})
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tLABEL\tIN\tOUT\tUNDIRECTED\tTOTAL")
+	for _, d := range degrees {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%d\t%d\n", d.Node.ID, d.Node.Label, d.In, d.Out, d.Undirected, d.Total())
+	}
+	return w.Flush()
+}
+
+// edgeName describes an edge by its label, falling back to its ID.
+func edgeName(e client.Edge) string {
+	if e.Label != nil && *e.Label != "" {
+		return fmt.Sprintf("%s [%s]", *e.Label, e.ID)
+	}
+	return fmt.Sprintf("[%s]", e.ID)
+}
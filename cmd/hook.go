@@ -0,0 +1,196 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/tribal/tribal-cli/internal/config"
+	"github.com/tribal/tribal-cli/internal/git"
+	"github.com/tribal/tribal-cli/internal/graph"
+)
+
+var hookCmd = &cobra.Command{
+	Use:   "hook",
+	Short: "Run the git hooks installed by 'tribal init --git-hooks'",
+	Long: `Run tribal's part of a git hook. These commands are called by the hooks
+that 'tribal init --git-hooks' installs and are not usually run by hand.`,
+}
+
+var hookPreCommitCmd = &cobra.Command{
+	Use:   "pre-commit",
+	Short: "Validate every graph before a git commit",
+	Run: func(cmd *cobra.Command, args []string) {
+		problems, err := validateGraphs()
+		if err != nil {
+			fmt.Printf("Error validating graphs: %v\n", err)
+			os.Exit(1)
+		}
+		if problems > 0 {
+			fmt.Printf("tribal: %d problem(s) found in graphs. Fix them, or commit with --no-verify to skip this check.\n", problems)
+			os.Exit(1)
+		}
+	},
+}
+
+var hookPostCommitCmd = &cobra.Command{
+	Use:   "post-commit",
+	Short: "Commit the graphs changed by a git commit",
+	Long: `When git_auto_commit is enabled (tribal init --git-hooks --auto-commit),
+stage and commit every graph changed by the latest git commit, using the git
+commit message. Otherwise do nothing.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := autoCommitGraphs(); err != nil {
+			// A failing post-commit hook cannot undo the git commit, so
+			// only warn.
+			fmt.Printf("tribal: warning: %v\n", err)
+		}
+	},
+}
+
+func init() {
+	hookCmd.AddCommand(hookPreCommitCmd, hookPostCommitCmd)
+	rootCmd.AddCommand(hookCmd)
+}
+
+// hookScripts are the bodies of the managed blocks written into git hooks.
+// %s is replaced by the path of the tribal repository relative to the top
+// of the git work tree.
+var hookScripts = map[string]string{
+	"pre-commit": `# Installed by 'tribal init --git-hooks': validate graphs before committing.
+if command -v tribal >/dev/null 2>&1; then
+	(cd "./%s" && tribal hook pre-commit) || exit 1
+fi`,
+	"post-commit": `# Installed by 'tribal init --git-hooks': commit changed graphs with the git message.
+if command -v tribal >/dev/null 2>&1; then
+	(cd "./%s" && tribal hook post-commit)
+fi`,
+}
+
+func installGitHooks(autoCommit bool) error {
+	if !git.Available(".") {
+		return fmt.Errorf("not inside a git repository")
+	}
+	prefix, err := git.Prefix(".")
+	if err != nil {
+		return err
+	}
+
+	for _, name := range []string{"pre-commit", "post-commit"} {
+		path, err := git.InstallHook(".", name, fmt.Sprintf(hookScripts[name], prefix))
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Installed %s hook: %s\n", name, path)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	cfg.GitAutoCommit = autoCommit
+	if err := cfg.Save(); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+	if autoCommit {
+		fmt.Println("Graphs changed by a git commit will be committed with the git commit message")
+	}
+	return nil
+}
+
+// validateGraphs checks every graph file and prints the problems found.
+func validateGraphs() (int, error) {
+	entries, err := ioutil.ReadDir(graph.Dir())
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	problems := 0
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		path := filepath.Join(graph.Dir(), entry.Name())
+
+		g, err := graph.Load(path)
+		if err != nil {
+			fmt.Printf("%s: %v\n", path, err)
+			problems++
+			continue
+		}
+		for _, p := range g.Validate() {
+			fmt.Printf("%s: %v\n", path, p)
+			problems++
+		}
+	}
+	return problems, nil
+}
+
+func autoCommitGraphs() error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if !cfg.GitAutoCommit {
+		return nil
+	}
+
+	changed, err := git.ChangedFiles(".", "HEAD", graph.Dir())
+	if err != nil {
+		return err
+	}
+	message, err := git.Message(".", "HEAD")
+	if err != nil {
+		return err
+	}
+
+	// Staging a graph checks it out; give the user back the graph they had
+	// checked out once every changed graph is committed. Commits made
+	// within the same second get distinct IDs from graph.CommitGraph.
+	err = commitChangedGraphs(changed, message)
+	if restoreErr := restoreCurrentGraph(cfg.CurrentGraph, cfg.CurrentGraphFile); err == nil {
+		err = restoreErr
+	}
+	return err
+}
+
+func commitChangedGraphs(changed []string, message string) error {
+	for _, path := range changed {
+		if !strings.HasSuffix(path, ".json") {
+			continue
+		}
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			// The graph was deleted in this commit.
+			continue
+		}
+		if err := stageGraphFile(filepath.FromSlash(path)); err != nil {
+			return err
+		}
+		if err := commitGraph(message); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func restoreCurrentGraph(title, path string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if cfg.CurrentGraph == title && cfg.CurrentGraphFile == path {
+		return nil
+	}
+	cfg.CurrentGraph = title
+	cfg.CurrentGraphFile = path
+	if err := cfg.Save(); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+	return nil
+}
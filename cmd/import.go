@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/tribal/tribal-cli/internal/graph"
+	"github.com/tribal/tribal-cli/internal/importer"
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Import a graph from a DOT, Mermaid or GraphML file",
+	Long: `Import a diagram as a new graph under .tribal/graphs, or merge it into an
+existing graph with --merge. When merging, imported nodes are matched to
+existing nodes by label.
+
+The format is inferred from the file extension (.dot, .gv, .mmd, .mermaid,
+.graphml) unless --format is given.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		format, _ := cmd.Flags().GetString("format")
+		title, _ := cmd.Flags().GetString("title")
+		merge, _ := cmd.Flags().GetBool("merge")
+		graphTitle, _ := cmd.Flags().GetString("graph")
+
+		if err := importGraph(userPath(args[0]), format, title, merge, graphTitle); err != nil {
+			fmt.Printf("Error importing graph: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	importCmd.Flags().StringP("format", "f", "", "Input format: "+strings.Join(importer.Formats(), ", "))
+	importCmd.Flags().StringP("title", "t", "", "Title of the new graph (default: title from the file, or the file name)")
+	importCmd.Flags().Bool("merge", false, "Merge into an existing graph instead of creating a new one")
+	importCmd.Flags().StringP("graph", "g", "", "Graph to merge into (default: current graph)")
+	rootCmd.AddCommand(importCmd)
+}
+
+func importGraph(path, format, title string, merge bool, graphTitle string) error {
+	if _, err := os.Stat(".tribal"); os.IsNotExist(err) {
+		return fmt.Errorf("not a tribal repository. Run 'tribal init' first")
+	}
+
+	if format == "" {
+		detected, ok := importer.DetectFormat(path)
+		if !ok {
+			return fmt.Errorf("cannot infer format of %s. Use --format", path)
+		}
+		format = detected
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	imported, err := importer.Parse(format, data)
+	if err != nil {
+		return err
+	}
+
+	if merge {
+		f, err := graph.Open(graphTitle)
+		if err != nil {
+			return err
+		}
+
+		stats := importer.Merge(f.Graph, imported)
+		if err := f.Graph.Save(f.Path); err != nil {
+			return err
+		}
+
+		fmt.Printf("Merged %s into graph: %s\n", path, f.Graph.Title)
+		fmt.Printf("  Nodes: %d added, %d matched by label\n", stats.NodesAdded, stats.NodesMatched)
+		fmt.Printf("  Edges: %d added, %d already present\n", stats.EdgesAdded, stats.EdgesSkipped)
+		fmt.Printf("Graph file: %s\n", f.Path)
+		return nil
+	}
+
+	switch {
+	case title != "":
+		imported.Title = title
+	case imported.Title == "":
+		imported.Title = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+
+	graphPath := graph.PathFor(imported.Title)
+	if _, err := os.Stat(graphPath); err == nil {
+		return fmt.Errorf("graph %q already exists at %s. Use --title for a new graph or --merge to merge into it", imported.Title, graphPath)
+	}
+
+	if err := os.MkdirAll(graph.Dir(), 0755); err != nil {
+		return fmt.Errorf("failed to create graphs directory: %w", err)
+	}
+
+	imported.Metadata["imported_from"] = filepath.Base(path)
+	if err := imported.Save(graphPath); err != nil {
+		return err
+	}
+
+	fmt.Printf("Imported graph: %s\n", imported.Title)
+	fmt.Printf("  Nodes: %d\n", len(imported.Nodes))
+	fmt.Printf("  Edges: %d\n", len(imported.Edges))
+	fmt.Printf("Graph file: %s\n", graphPath)
+	fmt.Printf("\nUse 'tribal checkout -g\"%s\"' to work on it.\n", imported.Title)
+
+	return nil
+}
@@ -4,99 +4,231 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
-	"path/filepath"
 
 	"github.com/spf13/cobra"
+	"github.com/tribal/tribal-cli/internal/agents"
 	"github.com/tribal/tribal-cli/internal/config"
+	"github.com/tribal/tribal-cli/internal/git"
+	"github.com/tribal/tribal-cli/internal/graph"
+	"github.com/tribal/tribal-cli/internal/managed"
+	"github.com/tribal/tribal-cli/internal/plan"
 )
 
 var initCmd = &cobra.Command{
 	Use:   "init",
 	Short: "Initialize a tribal repository",
-	Long:  `Initialize a tribal repository by creating TRIBAL.md file and updating CLAUDE.md`,
+	Long: `Initialize a tribal repository by creating TRIBAL.md file and updating CLAUDE.md.
+
+Use --agents to write instructions for other coding assistants as well
+(claude, codex, cursor, copilot, gemini, windsurf, or all). The instructions
+are kept in a marked block, so running init again updates them in place.
+
+Running init in an existing repository is safe: the config and graphs are
+kept, and only the marked tribal sections of TRIBAL.md, the agent files and
+.gitignore are refreshed. --force replaces TRIBAL.md and resets the config to
+its defaults (graphs and commits are still kept). --dry-run prints the
+changes without making them.
+
+TRIBAL.md is rendered from a text/template template, by default the built-in
+one. --template selects another by path, or by name from the directories in
+$TRIBAL_TEMPLATE_PATH and then the user config directory
+(~/.config/tribal/templates/<name>.md.tmpl); the choice is remembered for
+'tribal docs regenerate'.
+
+Inside a git repository, .gitignore is set up to ignore the local config,
+the staging area and graphs fetched from remotes, while graphs and commits
+stay tracked.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		registryURL, _ := cmd.Flags().GetString("registry")
-		if err := initRepository(registryURL); err != nil {
+		gitHooks, _ := cmd.Flags().GetBool("git-hooks")
+		autoCommit, _ := cmd.Flags().GetBool("auto-commit")
+		agentNames, _ := cmd.Flags().GetStringSlice("agents")
+		force, _ := cmd.Flags().GetBool("force")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		selected, err := agents.Parse(agentNames)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		_, statErr := os.Stat(config.GetConfigPath())
+		existing := statErr == nil
+
+		template, _ := cmd.Flags().GetString("template")
+		opts := initOptions{RegistryURL: registryURL, Template: template, Agents: selected, Force: force}
+		changes, err := planInit(opts, existing)
+		if err != nil {
+			fmt.Printf("Error initializing repository: %v\n", err)
+			os.Exit(1)
+		}
+
+		if dryRun {
+			for _, c := range changes {
+				fmt.Println(c.Describe(true))
+			}
+			if gitHooks || autoCommit {
+				fmt.Println("Would install git pre-commit and post-commit hooks")
+			}
+			return
+		}
+
+		if err := applyInit(changes); err != nil {
 			fmt.Printf("Error initializing repository: %v\n", err)
 			os.Exit(1)
 		}
-		fmt.Println("Successfully initialized tribal repository")
-		
+		if existing {
+			fmt.Println("Reinitialized existing tribal repository")
+		} else {
+			fmt.Println("Successfully initialized tribal repository")
+		}
+
 		if registryURL != "" {
 			fmt.Printf("Registry URL set to: %s\n", registryURL)
 			fmt.Println("Use 'tribal login' to authenticate with the registry")
 		}
+
+		if gitHooks || autoCommit {
+			if err := installGitHooks(autoCommit); err != nil {
+				fmt.Printf("Error installing git hooks: %v\n", err)
+				os.Exit(1)
+			}
+		}
 	},
 }
 
 func init() {
 	initCmd.Flags().StringP("registry", "r", "", "Registry URL (default: http://localhost:8080)")
+	initCmd.Flags().Bool("git-hooks", false, "Install git hooks that validate graphs before each git commit")
+	initCmd.Flags().Bool("auto-commit", false, "With --git-hooks, commit changed graphs after each git commit using its message")
+	initCmd.Flags().StringSlice("agents", []string{"claude"}, "Coding assistants to write instructions for (claude, codex, cursor, copilot, gemini, windsurf, all)")
+	initCmd.Flags().String("template", "", "TRIBAL.md template: a name from the template directories, or a path to a file")
+	initCmd.Flags().Bool("force", false, "Replace TRIBAL.md and reset the config to its defaults")
+	initCmd.Flags().Bool("dry-run", false, "Print the changes init would make without making them")
 	rootCmd.AddCommand(initCmd)
 }
 
-func initRepository(registryURL string) error {
-	// Create TRIBAL.md file
-	tribalContent := `# Tribal Framework
-
-This repository uses the Tribal framework for graph-based development.
-
-## Overview
-
-Tribal is a framework for organizing and managing development work through graph structures.
-Each graph represents a specific feature, component, or concept within the codebase.
+// gitignoreEntries keep per-user state out of git: the config holds the
+// checked-out graph and remotes, staging, pushed and remotes are scratch
+// copies. Graphs and commits are shared.
+const gitignoreEntries = `.tribal/config.json
+.tribal/staging/
+.tribal/pushed/
+.tribal/remotes/`
+
+// initOptions are the settings given to 'tribal init'.
+type initOptions struct {
+	RegistryURL string
+	Template    string
+	Agents      []agents.Agent
+	Force       bool
+}
 
-## Usage
+// planInit lists the files init writes. Existing files only have their
+// tribal sections refreshed; the config is kept unless force is set.
+func planInit(opts initOptions, existing bool) ([]plan.Change, error) {
+	cfg := config.CreateDefaultConfig()
+	if existing && !opts.Force {
+		loaded, err := config.Load()
+		if err != nil {
+			return nil, err
+		}
+		cfg = loaded
+	}
+	if opts.RegistryURL != "" {
+		cfg.SetRegistryURL(opts.RegistryURL)
+	}
+	if opts.Template != "" {
+		cfg.DocsTemplate = opts.Template
+	}
 
-- Use 'tribal checkout -g"<graph title>"' to create or retrieve a graph
-- Use 'tribal search --context <description>' to find related graphs
-- Use 'tribal add -A' to stage your graph changes
-- Use 'tribal commit -m"<message>"' to commit your graph
-- Use 'tribal push' to push changes to the remote
+	var changes []plan.Change
+	add := func(c plan.Change, err error) error {
+		if err != nil {
+			return err
+		}
+		changes = append(changes, c)
+		return nil
+	}
 
-## Graph Structure
+	if err := add(planTribalDoc(cfg, opts.Force)); err != nil {
+		return nil, err
+	}
 
-Graphs consist of nodes and edges that represent relationships between code components,
-features, or concepts. Each node can contain markup for documentation and context.
-`
+	for _, a := range opts.Agents {
+		current, err := readOptional(a.Path)
+		if err != nil {
+			return nil, err
+		}
+		if err := add(plan.File(a.Path, []byte(a.Render(current)), a.Name+" instructions")); err != nil {
+			return nil, err
+		}
+	}
 
-	if err := ioutil.WriteFile("TRIBAL.md", []byte(tribalContent), 0644); err != nil {
-		return fmt.Errorf("failed to create TRIBAL.md: %w", err)
+	if err := add(planConfig(cfg, opts, existing)); err != nil {
+		return nil, err
 	}
 
-	// Update or create CLAUDE.md
-	claudeContent := `This repository is developed using the TRIBAL framework. Whenever planning or making changes to the codebase, be sure to first consult TRIBAL.md in the root repository for reference.
+	if git.Available(".") {
+		current, err := readOptional(".gitignore")
+		if err != nil {
+			return nil, err
+		}
+		content := managed.Apply(current, gitignoreEntries, managed.ShellMarkers, false)
+		if err := add(plan.File(".gitignore", []byte(content), "ignore local tribal state")); err != nil {
+			return nil, err
+		}
+	}
 
-`
+	return changes, nil
+}
 
-	claudePath := "CLAUDE.md"
-	if _, err := os.Stat(claudePath); err == nil {
-		// File exists, prepend to existing content
-		existing, err := ioutil.ReadFile(claudePath)
-		if err != nil {
-			return fmt.Errorf("failed to read existing CLAUDE.md: %w", err)
+// planConfig writes the default config for a new repository, or keeps the
+// existing one, only updating the settings given on the command line.
+func planConfig(cfg *config.Config, opts initOptions, existing bool) (plan.Change, error) {
+	path := config.GetConfigPath()
+	note := "defaults"
+	if existing && !opts.Force {
+		if opts.RegistryURL == "" && opts.Template == "" {
+			return plan.Change{Path: path, Action: plan.Unchanged, Note: "kept"}, nil
 		}
-		claudeContent += string(existing)
+		note = "settings updated"
 	}
 
-	if err := ioutil.WriteFile(claudePath, []byte(claudeContent), 0644); err != nil {
-		return fmt.Errorf("failed to update CLAUDE.md: %w", err)
+	data, err := cfg.Encode()
+	if err != nil {
+		return plan.Change{}, err
 	}
+	return plan.File(path, data, note)
+}
 
+// applyInit creates the repository directories and writes the planned
+// files.
+func applyInit(changes []plan.Change) error {
 	// Create .tribal directory for internal storage
-	tribalDir := ".tribal"
-	if err := os.MkdirAll(tribalDir, 0755); err != nil {
+	if err := os.MkdirAll(graph.Dir(), 0755); err != nil {
 		return fmt.Errorf("failed to create .tribal directory: %w", err)
 	}
 
-	// Create initial config using the new config system
-	cfg := config.CreateDefaultConfig()
-	if registryURL != "" {
-		cfg.SetRegistryURL(registryURL)
+	for _, c := range changes {
+		if err := c.Apply(); err != nil {
+			return err
+		}
+		if c.Action != plan.Unchanged {
+			fmt.Println(c.Describe(false))
+		}
 	}
+	return nil
+}
 
-	if err := cfg.Save(); err != nil {
-		return fmt.Errorf("failed to create config: %w", err)
+// readOptional returns the content of path, or "" if it does not exist.
+func readOptional(path string) (string, error) {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return "", nil
 	}
-
-	return nil
-}
\ No newline at end of file
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return string(data), nil
+}
@@ -3,6 +3,7 @@ package cmd
 import (
 	"bufio"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"strings"
 	"syscall"
@@ -16,13 +17,22 @@ import (
 var loginCmd = &cobra.Command{
 	Use:   "login",
 	Short: "Login to tribal registry",
-	Long:  `Authenticate with the tribal registry server`,
+	Long: `Authenticate with the tribal registry server.
+
+Without flags, login prompts for a username and password. In scripts and CI,
+pipe the secret on stdin rather than passing it with --password, where it is
+visible to other users and kept in shell history:
+
+  echo "$TRIBAL_PAT" | tribal login --token-stdin
+  tribal login -u ci-bot --password-stdin < password.txt
+
+Instead of logging in, TRIBAL_TOKEN can hold a token for the registry. When
+stdin is not a terminal, login fails rather than waiting for input.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		username, _ := cmd.Flags().GetString("username")
-		password, _ := cmd.Flags().GetString("password")
-		registryURL, _ := cmd.Flags().GetString("registry")
+		opts := authFlags(cmd)
+		opts.TokenStdin, _ = cmd.Flags().GetBool("token-stdin")
 
-		if err := loginToRegistry(username, password, registryURL); err != nil {
+		if err := loginToRegistry(opts); err != nil {
 			fmt.Printf("Error logging in: %v\n", err)
 			os.Exit(1)
 		}
@@ -34,11 +44,15 @@ var logoutCmd = &cobra.Command{
 	Short: "Logout from tribal registry",
 	Long:  `Clear authentication credentials`,
 	Run: func(cmd *cobra.Command, args []string) {
-		if err := logoutFromRegistry(); err != nil {
+		remoteName, _ := cmd.Flags().GetString("remote")
+		if err := logoutFromRegistry(remoteName); err != nil {
 			fmt.Printf("Error logging out: %v\n", err)
 			os.Exit(1)
 		}
 		fmt.Println("Successfully logged out from tribal registry")
+		if os.Getenv("TRIBAL_TOKEN") != "" {
+			fmt.Println("Note: TRIBAL_TOKEN is set and is still used")
+		}
 	},
 }
 
@@ -47,12 +61,10 @@ var registerCmd = &cobra.Command{
 	Short: "Register a new account",
 	Long:  `Create a new account on the tribal registry`,
 	Run: func(cmd *cobra.Command, args []string) {
-		username, _ := cmd.Flags().GetString("username")
-		email, _ := cmd.Flags().GetString("email")
-		password, _ := cmd.Flags().GetString("password")
-		registryURL, _ := cmd.Flags().GetString("registry")
+		opts := authFlags(cmd)
+		opts.Email, _ = cmd.Flags().GetString("email")
 
-		if err := registerAccount(username, email, password, registryURL); err != nil {
+		if err := registerAccount(opts); err != nil {
 			fmt.Printf("Error registering: %v\n", err)
 			os.Exit(1)
 		}
@@ -62,25 +74,104 @@ var registerCmd = &cobra.Command{
 func init() {
 	// Login command flags
 	loginCmd.Flags().StringP("username", "u", "", "Username")
-	loginCmd.Flags().StringP("password", "p", "", "Password (will prompt if not provided)")
+	loginCmd.Flags().StringP("password", "p", "", "Password (will prompt if not provided; prefer --password-stdin)")
+	loginCmd.Flags().Bool("password-stdin", false, "Read the password from stdin")
+	loginCmd.Flags().Bool("token-stdin", false, "Log in with an access token read from stdin")
 	loginCmd.Flags().StringP("registry", "r", "", "Registry URL (uses config default if not provided)")
+	loginCmd.Flags().String("remote", "", "Log in to the registry of this remote")
+
+	// Logout command flags
+	logoutCmd.Flags().String("remote", "", "Log out of the registry of this remote")
 
 	// Register command flags
 	registerCmd.Flags().StringP("username", "u", "", "Username")
 	registerCmd.Flags().StringP("email", "e", "", "Email address")
-	registerCmd.Flags().StringP("password", "p", "", "Password (will prompt if not provided)")
+	registerCmd.Flags().StringP("password", "p", "", "Password (will prompt if not provided; prefer --password-stdin)")
+	registerCmd.Flags().Bool("password-stdin", false, "Read the password from stdin")
 	registerCmd.Flags().StringP("registry", "r", "", "Registry URL (uses config default if not provided)")
+	registerCmd.Flags().String("remote", "", "Register on the registry of this remote")
 
 	rootCmd.AddCommand(loginCmd)
 	rootCmd.AddCommand(logoutCmd)
 	rootCmd.AddCommand(registerCmd)
 }
 
+// authOptions are the flags shared by login and register.
+type authOptions struct {
+	Username      string
+	Email         string
+	Password      string
+	PasswordStdin bool
+	TokenStdin    bool
+	Registry      string
+	Remote        string
+}
+
+func authFlags(cmd *cobra.Command) authOptions {
+	var opts authOptions
+	opts.Username, _ = cmd.Flags().GetString("username")
+	opts.Password, _ = cmd.Flags().GetString("password")
+	opts.PasswordStdin, _ = cmd.Flags().GetBool("password-stdin")
+	opts.Registry, _ = cmd.Flags().GetString("registry")
+	opts.Remote, _ = cmd.Flags().GetString("remote")
+	if opts.Password != "" {
+		fmt.Fprintln(os.Stderr, "Warning: --password is visible to other users and saved in shell history. Use --password-stdin.")
+	}
+	return opts
+}
+
+// readPasswordStdin fills in the password from stdin for --password-stdin.
+func (opts *authOptions) readPasswordStdin() error {
+	if !opts.PasswordStdin {
+		return nil
+	}
+	if opts.Password != "" {
+		return fmt.Errorf("--password and --password-stdin cannot be used together")
+	}
+	if opts.Username == "" {
+		return fmt.Errorf("--password-stdin requires --username")
+	}
+	password, err := readStdinSecret("password")
+	if err != nil {
+		return err
+	}
+	opts.Password = password
+	return nil
+}
+
+func stdinIsTerminal() bool {
+	return terminal.IsTerminal(int(syscall.Stdin))
+}
+
+// noTerminal is returned instead of prompting when stdin is not a
+// terminal, where a prompt would wait forever.
+func noTerminal(what, hint string) error {
+	return fmt.Errorf("cannot prompt for %s: stdin is not a terminal. %s", what, hint)
+}
+
+// readStdinSecret reads a password or token piped on stdin, up to EOF,
+// without its trailing newline.
+func readStdinSecret(what string) (string, error) {
+	data, err := ioutil.ReadAll(os.Stdin)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s from stdin: %w", what, err)
+	}
+	secret := strings.TrimRight(string(data), "\r\n")
+	if strings.TrimSpace(secret) == "" {
+		return "", fmt.Errorf("no %s on stdin", what)
+	}
+	return secret, nil
+}
+
 func getCredentials(username, password string) (string, string, error) {
 	reader := bufio.NewReader(os.Stdin)
+	interactive := stdinIsTerminal()
 
 	// Get username if not provided
 	if username == "" {
+		if !interactive {
+			return "", "", noTerminal("a username", "Pass --username, or use --token-stdin.")
+		}
 		fmt.Print("Username: ")
 		input, err := reader.ReadString('\n')
 		if err != nil {
@@ -91,6 +182,9 @@ func getCredentials(username, password string) (string, string, error) {
 
 	// Get password if not provided
 	if password == "" {
+		if !interactive {
+			return "", "", noTerminal("a password", "Use --password-stdin or --token-stdin.")
+		}
 		fmt.Print("Password: ")
 		passwordBytes, err := terminal.ReadPassword(int(syscall.Stdin))
 		if err != nil {
@@ -105,9 +199,13 @@ func getCredentials(username, password string) (string, string, error) {
 
 func getRegistrationInfo(username, email, password string) (string, string, string, error) {
 	reader := bufio.NewReader(os.Stdin)
+	interactive := stdinIsTerminal()
 
 	// Get username if not provided
 	if username == "" {
+		if !interactive {
+			return "", "", "", noTerminal("a username", "Pass --username.")
+		}
 		fmt.Print("Username: ")
 		input, err := reader.ReadString('\n')
 		if err != nil {
@@ -118,6 +216,9 @@ func getRegistrationInfo(username, email, password string) (string, string, stri
 
 	// Get email if not provided
 	if email == "" {
+		if !interactive {
+			return "", "", "", noTerminal("an email address", "Pass --email.")
+		}
 		fmt.Print("Email: ")
 		input, err := reader.ReadString('\n')
 		if err != nil {
@@ -128,6 +229,9 @@ func getRegistrationInfo(username, email, password string) (string, string, stri
 
 	// Get password if not provided
 	if password == "" {
+		if !interactive {
+			return "", "", "", noTerminal("a password", "Use --password-stdin.")
+		}
 		fmt.Print("Password: ")
 		passwordBytes, err := terminal.ReadPassword(int(syscall.Stdin))
 		if err != nil {
@@ -140,16 +244,63 @@ func getRegistrationInfo(username, email, password string) (string, string, stri
 	return username, email, password, nil
 }
 
-func loginToRegistry(username, password, registryURL string) error {
-	// Load config
-	cfg, err := config.Load()
+// loadAuthConfig returns the repository config, or the user's settings
+// outside a repository, since credentials are stored per user. With a
+// remote, the config is pointed at the remote's registry and its login.
+// saveRepo tells whether the repository config should be saved as well.
+func loadAuthConfig(remoteName, registryURL string) (cfg *config.Config, saveRepo bool, err error) {
+	if remoteName != "" && registryURL != "" {
+		return nil, false, fmt.Errorf("--remote and --registry cannot be used together")
+	}
+	if _, err := os.Stat(config.GetConfigPath()); os.IsNotExist(err) {
+		if remoteName != "" {
+			return nil, false, fmt.Errorf("not a tribal repository. --remote needs one")
+		}
+		return config.CreateDefaultConfig(), false, nil
+	}
+	cfg, err = config.Load()
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to load config: %w", err)
+	}
+	if remoteName == "" {
+		return cfg, true, nil
+	}
+
+	r, ok := cfg.Remotes[remoteName]
+	if !ok {
+		return nil, false, fmt.Errorf("no such remote: %s", remoteName)
+	}
+	cred, err := cfg.Credentials(r.URL)
+	if err != nil {
+		return nil, false, err
+	}
+	cfg.SetRegistryURL(r.URL)
+	cfg.SetAuth(cred.Token, cred.Username, cred.UserID)
+	return cfg, false, nil
+}
+
+// saveAuth stores the credentials, and the repository config too when
+// saveRepo is set, which records a registry given with --registry.
+func saveAuth(cfg *config.Config, saveRepo bool) error {
+	if saveRepo {
+		return cfg.Save()
+	}
+	return cfg.SaveCredentials()
+}
+
+func loginToRegistry(opts authOptions) error {
+	if opts.TokenStdin && (opts.PasswordStdin || opts.Password != "" || opts.Username != "") {
+		return fmt.Errorf("--token-stdin cannot be used with a username or password")
+	}
+
+	cfg, saveRepo, err := loadAuthConfig(opts.Remote, opts.Registry)
 	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
+		return err
 	}
 
 	// Use provided registry URL or config default
-	if registryURL != "" {
-		cfg.SetRegistryURL(registryURL)
+	if opts.Registry != "" {
+		cfg.SetRegistryURL(opts.Registry)
 	}
 
 	// Create client
@@ -164,8 +315,15 @@ func loginToRegistry(username, password, registryURL string) error {
 		return fmt.Errorf("cannot connect to registry at %s: %w", cfg.RegistryURL, err)
 	}
 
+	if opts.TokenStdin {
+		return loginWithToken(c, cfg, saveRepo)
+	}
+
 	// Get credentials
-	username, password, err = getCredentials(username, password)
+	if err := opts.readPasswordStdin(); err != nil {
+		return err
+	}
+	username, password, err := getCredentials(opts.Username, opts.Password)
 	if err != nil {
 		return err
 	}
@@ -176,9 +334,9 @@ func loginToRegistry(username, password, registryURL string) error {
 		return fmt.Errorf("login failed: %w", err)
 	}
 
-	// Save auth info to config
+	// Save auth info to the credential store
 	cfg.SetAuth(authResp.Token, authResp.User.Username, authResp.User.ID.String())
-	if err := cfg.Save(); err != nil {
+	if err := saveAuth(cfg, saveRepo); err != nil {
 		return fmt.Errorf("failed to save authentication: %w", err)
 	}
 
@@ -186,36 +344,54 @@ func loginToRegistry(username, password, registryURL string) error {
 	return nil
 }
 
-func logoutFromRegistry() error {
-	// Load config
-	cfg, err := config.Load()
+// loginWithToken checks a token read from stdin with the registry and
+// stores it with the user it belongs to.
+func loginWithToken(c *client.Client, cfg *config.Config, saveRepo bool) error {
+	token, err := readStdinSecret("token")
+	if err != nil {
+		return err
+	}
+	token = strings.TrimSpace(token)
+
+	c.SetToken(token)
+	user, err := c.GetMe()
+	if err != nil {
+		return fmt.Errorf("token was not accepted by %s: %w", cfg.RegistryURL, err)
+	}
+
+	cfg.SetAuth(token, user.Username, user.ID.String())
+	if err := saveAuth(cfg, saveRepo); err != nil {
+		return fmt.Errorf("failed to save authentication: %w", err)
+	}
+
+	fmt.Printf("Successfully logged in as %s\n", user.Username)
+	return nil
+}
+
+func logoutFromRegistry(remoteName string) error {
+	cfg, saveRepo, err := loadAuthConfig(remoteName, "")
 	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
+		return err
 	}
 
 	// Clear auth
 	cfg.ClearAuth()
-	if err := cfg.Save(); err != nil {
+	if err := saveAuth(cfg, saveRepo); err != nil {
 		return fmt.Errorf("failed to save config: %w", err)
 	}
 
 	return nil
 }
 
-func registerAccount(username, email, password, registryURL string) error {
-	// Load config (create default if doesn't exist)
-	var cfg *config.Config
-	loadedCfg, err := config.Load()
+func registerAccount(opts authOptions) error {
+	cfg, saveRepo, err := loadAuthConfig(opts.Remote, opts.Registry)
 	if err != nil {
-		// If no config exists, create a default one
-		cfg = config.CreateDefaultConfig()
-	} else {
-		cfg = loadedCfg
+		return err
 	}
 
 	// Use provided registry URL or config default
-	if registryURL != "" {
-		cfg.SetRegistryURL(registryURL)
+	if opts.Registry != "" {
+		cfg.SetRegistryURL(opts.Registry)
 	}
 
 	// Create client
@@ -231,7 +407,10 @@ func registerAccount(username, email, password, registryURL string) error {
 	}
 
 	// Get registration info
-	username, email, password, err = getRegistrationInfo(username, email, password)
+	if err := opts.readPasswordStdin(); err != nil {
+		return err
+	}
+	username, email, password, err := getRegistrationInfo(opts.Username, opts.Email, opts.Password)
 	if err != nil {
 		return err
 	}
@@ -242,9 +421,9 @@ func registerAccount(username, email, password, registryURL string) error {
 		return fmt.Errorf("registration failed: %w", err)
 	}
 
-	// Save auth info to config
+	// Save auth info to the credential store
 	cfg.SetAuth(authResp.Token, authResp.User.Username, authResp.User.ID.String())
-	if err := cfg.Save(); err != nil {
+	if err := saveAuth(cfg, saveRepo); err != nil {
 		return fmt.Errorf("failed to save authentication: %w", err)
 	}
 
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"runtime/debug"
+
+	"github.com/spf13/cobra"
+	"github.com/tribal/tribal-cli/internal/mcp"
+)
+
+var mcpCmd = &cobra.Command{
+	Use:   "mcp",
+	Short: "Run a Model Context Protocol server over stdio",
+	Long: `Run a Model Context Protocol (MCP) server on stdin and stdout so coding
+agents can search, read and edit the repository's graphs with structured JSON.
+
+Tools: list_graphs, search, checkout, get_graph, get_node, add_node,
+update_node, delete_node, add_edge, update_edge, delete_edge, diff and commit.
+Every graph is also exposed as a resource at tribal://graphs/<name>, and every
+node at tribal://graphs/<name>/nodes/<id>.
+
+Configure your agent to launch 'tribal mcp' from the repository root.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		server := mcp.NewServer(buildVersion(), os.Stderr)
+		if err := server.Serve(os.Stdin, os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Error running MCP server: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(mcpCmd)
+}
+
+// buildVersion returns the module version the binary was built from.
+func buildVersion() string {
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+	return "dev"
+}
@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/tribal/tribal-cli/internal/anchor"
+	"github.com/tribal/tribal-cli/internal/client"
+	"github.com/tribal/tribal-cli/internal/graph"
+)
+
+var nodeCmd = &cobra.Command{
+	Use:   "node",
+	Short: "Manage the nodes of the current graph",
+	Long: `Manage the nodes of the current graph (or the graph given with -g).
+Nodes may be referred to by ID or by label.`,
+}
+
+var nodeLinkCmd = &cobra.Command{
+	Use:   "link <node> <path[:line[-end]]|pkg.Symbol>",
+	Short: "Link a node to a file, line range or Go symbol",
+	Long: `Add a code anchor to a node. The location is a file or directory path,
+optionally followed by :line or :start-end, or a Go declaration written as
+pkg.Name or pkg.Type.Method, where pkg is a package name, a directory or an
+import path. The location must exist in the working tree. A hash of its
+content is recorded so 'tribal drift' can report when the code changes.`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		runNodeCommand(cmd, func(f *graph.File) error {
+			return linkNode(f, args[0], userRef(args[1]))
+		})
+	},
+}
+
+var nodeUnlinkCmd = &cobra.Command{
+	Use:   "unlink <node> [path|pkg.Symbol]",
+	Short: "Remove code anchors from a node",
+	Long: `Remove the anchors of a node that refer to the given path or symbol, or
+every anchor of the node when none is given.`,
+	Args: cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		runNodeCommand(cmd, func(f *graph.File) error {
+			target := ""
+			if len(args) > 1 {
+				target = userRef(args[1])
+			}
+			return unlinkNode(f, args[0], target)
+		})
+	},
+}
+
+func init() {
+	nodeCmd.PersistentFlags().StringP("graph", "g", "", "Graph title (default: current graph)")
+	nodeCmd.AddCommand(nodeLinkCmd, nodeUnlinkCmd)
+	rootCmd.AddCommand(nodeCmd)
+}
+
+// workTree is the directory anchors are resolved against: the one holding
+// .tribal, which commands run from.
+func workTree() string {
+	return "."
+}
+
+func runNodeCommand(cmd *cobra.Command, fn func(f *graph.File) error) {
+	title, _ := cmd.Flags().GetString("graph")
+
+	f, err := graph.Open(title)
+	if err == nil {
+		err = fn(f)
+	}
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func linkNode(f *graph.File, ref, location string) error {
+	n, err := f.Graph.Resolve(ref)
+	if err != nil {
+		return err
+	}
+	node := f.Graph.Node(n.ID)
+
+	a, err := anchor.Resolve(workTree(), location)
+	if err != nil {
+		return err
+	}
+	if err := anchor.Record(workTree(), &a); err != nil {
+		return err
+	}
+	for _, existing := range node.Anchors {
+		if anchor.Equal(existing, a) {
+			fmt.Printf("%s is already linked to %s\n", node.Label, anchor.String(a))
+			return nil
+		}
+	}
+
+	node.Anchors = append(node.Anchors, a)
+	if err := f.Graph.Save(f.Path); err != nil {
+		return err
+	}
+
+	fmt.Printf("Linked %s (%s) to %s\n", node.Label, node.ID, anchor.String(a))
+	return nil
+}
+
+func unlinkNode(f *graph.File, ref, target string) error {
+	n, err := f.Graph.Resolve(ref)
+	if err != nil {
+		return err
+	}
+	node := f.Graph.Node(n.ID)
+
+	path := target
+	if target != "" {
+		if rel, err := anchor.Rel(workTree(), target); err == nil {
+			path = rel
+		}
+	}
+
+	var kept []client.Anchor
+	removed := 0
+	for _, a := range node.Anchors {
+		if target == "" || a.Symbol == target || a.Path == path {
+			removed++
+			continue
+		}
+		kept = append(kept, a)
+	}
+	if removed == 0 {
+		return fmt.Errorf("%s has no anchors matching %q", node.Label, target)
+	}
+
+	node.Anchors = kept
+	if err := f.Graph.Save(f.Path); err != nil {
+		return err
+	}
+
+	fmt.Printf("Removed %d anchor(s) from %s (%s)\n", removed, node.Label, node.ID)
+	return nil
+}
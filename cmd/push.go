@@ -8,14 +8,39 @@ import (
 	"path/filepath"
 
 	"github.com/spf13/cobra"
+	"github.com/tribal/tribal-cli/internal/config"
+	"github.com/tribal/tribal-cli/internal/graph"
+	"github.com/tribal/tribal-cli/internal/remote"
 )
 
 var pushCmd = &cobra.Command{
-	Use:   "push",
+	Use:   "push [remote]",
 	Short: "Push committed graph changes",
-	Long:  `Push the latest commit to the remote repository`,
+	Long: `Push the latest commit to a remote registry.
+
+Without a remote, the graph's upstream is used, then the default remote.
+-g pushes the latest commit of another graph, so selected graphs can be
+mirrored to a second registry. The first push creates the graph on the
+registry; later pushes update it.
+
+Without any remotes configured, the commit is copied to .tribal/pushed.`,
+	Args: cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		if err := pushGraph(); err != nil {
+		title, _ := cmd.Flags().GetString("graph")
+		setUpstream, _ := cmd.Flags().GetBool("set-upstream")
+		public, _ := cmd.Flags().GetBool("public")
+
+		var err error
+		if len(args) == 0 && title == "" && !hasRemotes() {
+			err = pushGraph()
+		} else {
+			name := ""
+			if len(args) > 0 {
+				name = args[0]
+			}
+			err = pushToRemote(name, title, setUpstream, public)
+		}
+		if err != nil {
 			fmt.Printf("Error pushing graph: %v\n", err)
 			os.Exit(1)
 		}
@@ -23,9 +48,96 @@ var pushCmd = &cobra.Command{
 }
 
 func init() {
+	pushCmd.Flags().StringP("graph", "g", "", "Push the latest commit of this graph instead of the latest commit")
+	pushCmd.Flags().BoolP("set-upstream", "u", false, "Make the remote the graph's upstream")
+	pushCmd.Flags().Bool("public", false, "Make the graph public on the registry")
 	rootCmd.AddCommand(pushCmd)
 }
 
+func hasRemotes() bool {
+	cfg, err := config.Load()
+	return err == nil && len(cfg.Remotes) > 0
+}
+
+// pushToRemote publishes a commit's graph to a remote and records the
+// registry's ID for it.
+func pushToRemote(name, title string, setUpstream, public bool) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	if name != "" {
+		// Report an unknown remote before looking for something to push.
+		if _, _, err := cfg.ResolveRemote(name, ""); err != nil {
+			return err
+		}
+	}
+
+	var commit *graph.Commit
+	if title != "" {
+		if commit, err = graph.LatestCommit(title); err != nil {
+			return err
+		}
+		if commit == nil {
+			return fmt.Errorf("graph %s has no commits. Use 'tribal commit -m\"<message>\"' first", title)
+		}
+	} else {
+		if cfg.LatestCommit == "" {
+			return fmt.Errorf("no commits to push. Use 'tribal commit -m\"<message>\"' first")
+		}
+		if commit, err = graph.LoadCommit(cfg.LatestCommit); err != nil {
+			return err
+		}
+	}
+	title = commit.Graph.Title
+
+	name, r, err := cfg.ResolveRemote(name, title)
+	if err != nil {
+		return err
+	}
+	cred, err := cfg.Credentials(r.URL)
+	if err != nil {
+		return err
+	}
+	if cred.Token == "" {
+		return fmt.Errorf("not logged in to %s (%s). Run 'tribal login --remote %s'", name, r.URL, name)
+	}
+	c, err := remote.Client(cfg, r)
+	if err != nil {
+		return err
+	}
+
+	t := cfg.TrackingFor(title)
+	pushed, err := remote.Push(c, commit.Graph, t.IDs[name], commit.Message, public)
+	if err != nil {
+		return err
+	}
+	t.IDs[name] = pushed.ID.String()
+	if setUpstream {
+		t.Upstream = name
+	}
+	cfg.LastPushedCommit = commit.ID
+
+	if _, err := remote.Save(name, remote.FromRegistry(pushed)); err != nil {
+		return err
+	}
+	if err := cfg.Save(); err != nil {
+		return fmt.Errorf("failed to update config: %w", err)
+	}
+
+	fmt.Printf("Pushed commit: %s\n", commit.ID)
+	fmt.Printf("Message: %s\n", commit.Message)
+	fmt.Printf("Pushed to: %s (%s)\n", name, r.URL)
+	fmt.Printf("Graph: %s\n", title)
+	fmt.Printf("Registry ID: %s\n", pushed.ID)
+	fmt.Printf("Nodes: %d\n", len(commit.Graph.Nodes))
+	fmt.Printf("Edges: %d\n", len(commit.Graph.Edges))
+	if setUpstream {
+		fmt.Printf("Graph %s now tracks %s\n", title, name)
+	}
+	return nil
+}
+
 func pushGraph() error {
 	// Check if .tribal exists
 	if _, err := os.Stat(".tribal"); os.IsNotExist(err) {
@@ -114,7 +226,7 @@ func pushGraph() error {
 	}
 
 	fmt.Println("\nGraph successfully pushed!")
-	fmt.Println("Note: This is a local simulation. In production, this would sync with a remote tribal server.")
+	fmt.Println("Note: No remotes are configured, so the commit was only copied locally. Add one with 'tribal remote add <name> <url>'.")
 
 	return nil
 }
\ No newline at end of file
@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/tribal/tribal-cli/internal/client"
+	"github.com/tribal/tribal-cli/internal/graph"
+	"github.com/tribal/tribal-cli/internal/query"
+)
+
+var queryCmd = &cobra.Command{
+	Use:   "query '<expr>'",
+	Short: "Query a graph with path patterns",
+	Long: `Query the current graph with a path pattern and print the matching nodes.
+
+A pattern is a node followed by any number of edge/node pairs:
+
+  (<predicate>) -[<predicate> *<min>..<max>]-> (<predicate>)
+
+Edges are written -[...]-> (outgoing), <-[...]- (incoming) or -[...]- (either
+direction, including undirected edges). The brackets are optional. Depth
+ranges are measured along shortest paths: *N exact, *N..M between, *N.. at
+least, *..M at most, * one or more. Without a range an edge is a single hop.
+
+Predicates compare id, label or markup (plus source and target labels on
+edges) using = and != (case-insensitive), ~ (contains), =~ and !~ (regular
+expression), combined with and, or, not and parentheses. A bare string is
+shorthand for label = "...", as is a bare word such as -[calls]->.
+
+Examples:
+  tribal query "('Auth Service') -[calls *]-> ()"
+  tribal query "(markup ~ 'postgres') <- (label =~ '^API')"
+  tribal query "(id = 'n1') -[*..2]- ()" --format graph --out neighborhood.json`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		graphTitle, _ := cmd.Flags().GetString("graph")
+		format, _ := cmd.Flags().GetString("format")
+		out, _ := cmd.Flags().GetString("out")
+		out = userPath(out)
+
+		if err := runQuery(args[0], graphTitle, format, out); err != nil {
+			fmt.Printf("Error running query: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	queryCmd.Flags().StringP("graph", "g", "", "Graph title to query (default: current graph)")
+	queryCmd.Flags().StringP("format", "f", "table", "Output format: table, json or graph")
+	queryCmd.Flags().StringP("out", "o", "", "Write output to a file instead of stdout")
+	rootCmd.AddCommand(queryCmd)
+}
+
+func runQuery(expr, graphTitle, format, out string) error {
+	q, err := query.Parse(expr)
+	if err != nil {
+		return fmt.Errorf("invalid query: %w", err)
+	}
+
+	f, err := graph.Open(graphTitle)
+	if err != nil {
+		return err
+	}
+
+	result := query.Eval(q, f.Graph)
+
+	var output []byte
+	switch format {
+	case "table":
+		output = []byte(formatQueryTable(result.Nodes))
+	case "json":
+		output, err = marshalJSON(struct {
+			Query string        `json:"query"`
+			Graph string        `json:"graph"`
+			Nodes []client.Node `json:"nodes"`
+			Edges []client.Edge `json:"edges"`
+		}{expr, f.Graph.Title, nonNilNodes(result.Nodes), result.Subgraph.Edges})
+	case "graph":
+		sub := result.Subgraph
+		sub.Title = f.Graph.Title + " (query)"
+		sub.Metadata["description"] = fmt.Sprintf("Result of query %s on graph %s", expr, f.Graph.Title)
+		sub.Metadata["source_graph"] = f.Graph.Title
+		output, err = marshalJSON(sub)
+	default:
+		return fmt.Errorf("unknown format %q. Use table, json or graph", format)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to serialize result: %w", err)
+	}
+
+	if out == "" {
+		fmt.Print(string(output))
+		return nil
+	}
+
+	if err := ioutil.WriteFile(out, output, 0644); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	fmt.Printf("Wrote %d matching nodes to %s\n", len(result.Nodes), out)
+
+	return nil
+}
+
+func formatQueryTable(nodes []client.Node) string {
+	if len(nodes) == 0 {
+		return "No matching nodes.\n"
+	}
+
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tLABEL\tMARKUP")
+	for _, n := range nodes {
+		markup := ""
+		if n.Markup != nil {
+			markup = firstLine(*n.Markup, 60)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", n.ID, n.Label, markup)
+	}
+	w.Flush()
+	fmt.Fprintf(&b, "\n%d matching nodes\n", len(nodes))
+
+	return b.String()
+}
+
+// firstLine returns the first line of s, truncated to max runes.
+func firstLine(s string, max int) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		s = s[:i]
+	}
+	runes := []rune(strings.TrimSpace(s))
+	if len(runes) > max {
+		return string(runes[:max-3]) + "..."
+	}
+	return string(runes)
+}
+
+func nonNilNodes(nodes []client.Node) []client.Node {
+	if nodes == nil {
+		return []client.Node{}
+	}
+	return nodes
+}
+
+// marshalJSON indents v without escaping <, > and &, which appear in query
+// expressions and markup.
+func marshalJSON(v interface{}) ([]byte, error) {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
@@ -0,0 +1,235 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/tribal/tribal-cli/internal/client"
+	"github.com/tribal/tribal-cli/internal/config"
+	"github.com/tribal/tribal-cli/internal/remote"
+)
+
+var remoteCmd = &cobra.Command{
+	Use:   "remote",
+	Short: "Manage the registries graphs are pushed to",
+	Long: `Manage named remotes, the registries 'tribal push' and 'tribal fetch'
+talk to. Each remote has its own login: run 'tribal login --remote <name>'.
+
+The first remote added becomes the default; push and fetch use a graph's
+upstream (set with 'tribal push -u <remote>'), then the default remote.
+
+Without a subcommand, remotes are listed as with 'tribal remote list'.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		verbose, _ := cmd.Flags().GetBool("verbose")
+		if err := listRemotes(verbose); err != nil {
+			fmt.Printf("Error listing remotes: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var remoteAddCmd = &cobra.Command{
+	Use:   "add <name> <url>",
+	Short: "Add a remote",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		makeDefault, _ := cmd.Flags().GetBool("default")
+		isDefault := false
+		err := editRemotes(func(cfg *config.Config) error {
+			if err := validateRegistryURL(args[1]); err != nil {
+				return err
+			}
+			if err := cfg.AddRemote(args[0], args[1]); err != nil {
+				return err
+			}
+			if makeDefault {
+				cfg.Remote = args[0]
+			}
+			isDefault = cfg.Remote == args[0]
+			return nil
+		})
+		if err != nil {
+			fmt.Printf("Error adding remote: %v\n", err)
+			os.Exit(1)
+		}
+		if isDefault {
+			fmt.Printf("Added remote %s (%s) as the default remote\n", args[0], args[1])
+		} else {
+			fmt.Printf("Added remote %s (%s)\n", args[0], args[1])
+		}
+		fmt.Printf("Log in to it with 'tribal login --remote %s'\n", args[0])
+	},
+}
+
+var remoteRemoveCmd = &cobra.Command{
+	Use:     "remove <name>",
+	Aliases: []string{"rm"},
+	Short:   "Remove a remote and what is tracked on it",
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		err := editRemotes(func(cfg *config.Config) error {
+			return cfg.RemoveRemote(args[0])
+		})
+		if err == nil {
+			err = os.RemoveAll(remote.Dir(args[0]))
+		}
+		if err != nil {
+			fmt.Printf("Error removing remote: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Removed remote %s\n", args[0])
+	},
+}
+
+var remoteRenameCmd = &cobra.Command{
+	Use:   "rename <old> <new>",
+	Short: "Rename a remote",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		err := editRemotes(func(cfg *config.Config) error {
+			return cfg.RenameRemote(args[0], args[1])
+		})
+		if err == nil {
+			err = renameFetched(args[0], args[1])
+		}
+		if err != nil {
+			fmt.Printf("Error renaming remote: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Renamed remote %s to %s\n", args[0], args[1])
+	},
+}
+
+var remoteSetURLCmd = &cobra.Command{
+	Use:   "set-url <name> <url>",
+	Short: "Change the URL of a remote",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		err := editRemotes(func(cfg *config.Config) error {
+			if err := validateRegistryURL(args[1]); err != nil {
+				return err
+			}
+			return cfg.SetRemoteURL(args[0], args[1])
+		})
+		if err != nil {
+			fmt.Printf("Error setting remote URL: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Remote %s now points at %s\n", args[0], args[1])
+	},
+}
+
+var remoteListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List remotes",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		verbose, _ := cmd.Flags().GetBool("verbose")
+		if err := listRemotes(verbose); err != nil {
+			fmt.Printf("Error listing remotes: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	remoteAddCmd.Flags().Bool("default", false, "Make this the default remote")
+	remoteCmd.Flags().BoolP("verbose", "v", false, "Show logins and tracked graphs")
+	remoteListCmd.Flags().BoolP("verbose", "v", false, "Show logins and tracked graphs")
+
+	remoteCmd.AddCommand(remoteAddCmd)
+	remoteCmd.AddCommand(remoteRemoveCmd)
+	remoteCmd.AddCommand(remoteRenameCmd)
+	remoteCmd.AddCommand(remoteSetURLCmd)
+	remoteCmd.AddCommand(remoteListCmd)
+	rootCmd.AddCommand(remoteCmd)
+}
+
+// editRemotes loads the config, applies fn and saves the result.
+func editRemotes(fn func(cfg *config.Config) error) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	if err := fn(cfg); err != nil {
+		return err
+	}
+	return cfg.Save()
+}
+
+func validateRegistryURL(url string) error {
+	if err := client.NewClient(url).ValidateURL(); err != nil {
+		return fmt.Errorf("invalid registry URL: %w", err)
+	}
+	return nil
+}
+
+// renameFetched moves the graphs fetched from a remote along with it.
+func renameFetched(old, name string) error {
+	if _, err := os.Stat(remote.Dir(old)); os.IsNotExist(err) {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(remote.Dir(name)), 0755); err != nil {
+		return err
+	}
+	return os.Rename(remote.Dir(old), remote.Dir(name))
+}
+
+func listRemotes(verbose bool) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	if len(cfg.Remotes) == 0 {
+		fmt.Println("No remotes. Add one with 'tribal remote add <name> <url>'.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+	for _, name := range cfg.RemoteNames() {
+		r := cfg.Remotes[name]
+		marker := ""
+		if name == cfg.Remote {
+			marker = " (default)"
+		}
+		if !verbose {
+			fmt.Fprintf(w, "%s%s\t%s\n", name, marker, r.URL)
+			continue
+		}
+		login := "not logged in"
+		if cred, err := cfg.Credentials(r.URL); err == nil && cred.Token != "" {
+			login = "logged in"
+			if cred.Username != "" {
+				login += " as " + cred.Username
+			}
+		}
+		fmt.Fprintf(w, "%s%s\t%s\t%s\n", name, marker, r.URL, login)
+		for _, title := range trackedOn(cfg, name) {
+			t := cfg.Tracking[title]
+			upstream := ""
+			if t.Upstream == name {
+				upstream = " (upstream)"
+			}
+			fmt.Fprintf(w, "  %s%s\t%s\n", title, upstream, t.IDs[name])
+		}
+	}
+	return nil
+}
+
+// trackedOn returns the titles of the graphs published on a remote, sorted.
+func trackedOn(cfg *config.Config, name string) []string {
+	var titles []string
+	for title, t := range cfg.Tracking {
+		if _, ok := t.IDs[name]; ok || t.Upstream == name {
+			titles = append(titles, title)
+		}
+	}
+	sort.Strings(titles)
+	return titles
+}
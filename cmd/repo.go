@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/spf13/cobra"
+	"github.com/tribal/tribal-cli/internal/repo"
+)
+
+// invocationPrefix is the directory tribal was run from, relative to the
+// repository root. Commands run from the root, so paths given on the
+// command line are joined onto it with userPath.
+var invocationPrefix string
+
+// enterRepository changes to the directory given with --repo, then to the
+// root of the repository containing it, so every command sees .tribal in
+// the current directory. 'tribal init' creates a repository where it is
+// run instead of looking for one.
+func enterRepository(cmd *cobra.Command) error {
+	if dir, _ := cmd.Flags().GetString("repo"); dir != "" {
+		if err := os.Chdir(dir); err != nil {
+			return fmt.Errorf("cannot change to %s: %w", dir, err)
+		}
+	}
+
+	if cmd == initCmd {
+		if dir := os.Getenv(repo.EnvDir); dir != "" {
+			if err := os.Chdir(dir); err != nil {
+				return fmt.Errorf("cannot change to %s=%s: %w", repo.EnvDir, dir, err)
+			}
+		}
+		return nil
+	}
+
+	root, ok, err := repo.Find(".")
+	if err != nil || !ok {
+		// Without a repository, commands report it themselves
+		return err
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	if prefix, err := filepath.Rel(root, wd); err == nil && prefix != "." {
+		invocationPrefix = prefix
+	}
+	return os.Chdir(root)
+}
+
+// userPath resolves a path given on the command line, relative to the
+// directory tribal was run from, against the repository root.
+func userPath(path string) string {
+	if path == "" || invocationPrefix == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(invocationPrefix, path)
+}
+
+var userLineSuffix = regexp.MustCompile(`^(.+)(:\d+(?:-\d+)?)$`)
+
+// userRef is userPath for references that may carry a :line suffix or be a
+// Go symbol rather than a path. Symbols are returned unchanged.
+func userRef(ref string) string {
+	path, suffix := ref, ""
+	if m := userLineSuffix.FindStringSubmatch(ref); m != nil {
+		path, suffix = m[1], m[2]
+	}
+	if _, err := os.Stat(userPath(path)); err != nil {
+		return ref
+	}
+	return userPath(path) + suffix
+}
@@ -11,7 +11,17 @@ var rootCmd = &cobra.Command{
 	Use:   "tribal",
 	Short: "Tribal CLI - Graph-based development workflow",
 	Long: `Tribal CLI provides commands for managing graph-based development workflows.
-Use tribal to initialize repositories, manage graphs, and collaborate on graph structures.`,
+Use tribal to initialize repositories, manage graphs, and collaborate on graph structures.
+
+Commands run in the repository containing the current directory, found by
+looking for .tribal in it and its parents. Use -C to start from another
+directory, or set TRIBAL_DIR to the repository root.`,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		if err := enterRepository(cmd); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
 }
 
 func Execute() {
@@ -22,5 +32,6 @@ func Execute() {
 }
 
 func init() {
+	rootCmd.PersistentFlags().StringP("repo", "C", "", "Run as if tribal was started in this directory")
 	rootCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
 }
\ No newline at end of file
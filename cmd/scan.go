@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/tribal/tribal-cli/internal/anchor"
+	"github.com/tribal/tribal-cli/internal/graph"
+	"github.com/tribal/tribal-cli/internal/scan"
+)
+
+var scanCmd = &cobra.Command{
+	Use:   "scan",
+	Short: "Build graphs from source code",
+}
+
+var scanGoCmd = &cobra.Command{
+	Use:   "go [packages]",
+	Short: "Add the packages of a Go module to the current graph",
+	Long: `Parse the Go packages matching the given patterns (default ./...) and merge
+them into the current graph (or the graph given with -g): one node per
+package, with its doc comment as markup, and an "imports" edge for every
+import between scanned packages.
+
+With --types, every exported type also gets a node with a "declares" edge
+from its package, and types get "implements" edges to the scanned interfaces
+whose methods they have.
+
+Scanned nodes are anchored to their code. Package nodes are anchored to the
+Go files directly in the package directory, so changes to subpackages or to
+other files do not make them drift. Nodes already in the graph are
+matched by anchor or label and keep their label and markup, so the scan can
+be re-run as the code evolves. Run 'tribal layout' afterwards to position new
+nodes.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		graphTitle, _ := cmd.Flags().GetString("graph")
+		types, _ := cmd.Flags().GetBool("types")
+
+		// Patterns are relative to the directory tribal was run from
+		var patterns []string
+		for _, p := range args {
+			patterns = append(patterns, userPath(p))
+		}
+
+		if err := scanGo(graphTitle, patterns, scan.GoOptions{Types: types}); err != nil {
+			fmt.Printf("Error scanning Go packages: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	scanGoCmd.Flags().StringP("graph", "g", "", "Graph to merge into (default: current graph)")
+	scanGoCmd.Flags().Bool("types", false, "Add nodes for exported types and interfaces")
+	scanCmd.AddCommand(scanGoCmd)
+	rootCmd.AddCommand(scanCmd)
+}
+
+func scanGo(graphTitle string, patterns []string, opts scan.GoOptions) error {
+	f, err := graph.Open(graphTitle)
+	if err != nil {
+		return err
+	}
+
+	scanned, err := scan.Go(workTree(), patterns, opts)
+	if err != nil {
+		return err
+	}
+	for i := range scanned.Nodes {
+		for j := range scanned.Nodes[i].Anchors {
+			if err := anchor.Record(workTree(), &scanned.Nodes[i].Anchors[j]); err != nil {
+				return err
+			}
+		}
+	}
+
+	stats := scan.Merge(f.Graph, scanned)
+	if err := f.Graph.Save(f.Path); err != nil {
+		return err
+	}
+
+	fmt.Printf("Scanned %d nodes into graph: %s\n", len(scanned.Nodes), f.Graph.Title)
+	fmt.Printf("  Nodes: %d added, %d already present\n", stats.NodesAdded, stats.NodesMatched)
+	fmt.Printf("  Edges: %d added, %d already present\n", stats.EdgesAdded, stats.EdgesSkipped)
+	fmt.Printf("Graph file: %s\n", f.Path)
+	if stats.NodesAdded > 0 {
+		fmt.Println("\nRun 'tribal layout' to position the new nodes.")
+	}
+	return nil
+}
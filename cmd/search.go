@@ -1,28 +1,63 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
-	"io/ioutil"
 	"os"
-	"path/filepath"
 	"strings"
 
+	"github.com/google/uuid"
 	"github.com/spf13/cobra"
+	"github.com/tribal/tribal-cli/internal/client"
+	"github.com/tribal/tribal-cli/internal/config"
+	"github.com/tribal/tribal-cli/internal/graph"
+	"github.com/tribal/tribal-cli/internal/search"
+	"golang.org/x/term"
 )
 
 var searchCmd = &cobra.Command{
 	Use:   "search",
-	Short: "Search for graphs by semantic similarity",
-	Long:  `Search for graphs by title and description using semantic similarity matching`,
+	Short: "Search graphs, nodes and edges",
+	Long: `Search graph titles and descriptions, node labels and markup, and edge
+labels and markup. Results are ranked by how many query terms they match.
+
+Use --remote to search the registry instead of the local repository, or --all
+to search both and merge the results. Graphs found in both places are listed
+once.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		context, _ := cmd.Flags().GetString("context")
-		if context == "" {
+		remote, _ := cmd.Flags().GetBool("remote")
+		all, _ := cmd.Flags().GetBool("all")
+		public, _ := cmd.Flags().GetBool("public")
+		if context == "" && !((remote || all) && public) {
 			fmt.Println("Error: context description is required. Use --context flag.")
 			os.Exit(1)
 		}
 
-		if err := searchGraphs(context); err != nil {
+		kindFlag, _ := cmd.Flags().GetString("type")
+		kind, ok := search.ParseKind(kindFlag)
+		if !ok {
+			fmt.Printf("Error: invalid --type %q. Use node, edge or graph.\n", kindFlag)
+			os.Exit(1)
+		}
+
+		graphTitle, _ := cmd.Flags().GetString("graph")
+		owner, _ := cmd.Flags().GetString("owner")
+		limit, _ := cmd.Flags().GetInt("limit")
+		offset, _ := cmd.Flags().GetInt("offset")
+
+		opts := search.Options{Kind: kind, Graph: graphTitle}
+		remoteOpts := search.RemoteOptions{Public: public, Limit: limit, Offset: offset}
+
+		var err error
+		switch {
+		case all:
+			err = searchAll(context, opts, remoteOpts, owner)
+		case remote:
+			err = searchRemote(context, opts, remoteOpts, owner)
+		default:
+			err = searchGraphs(context, opts)
+		}
+		if err != nil {
 			fmt.Printf("Error searching graphs: %v\n", err)
 			os.Exit(1)
 		}
@@ -30,117 +65,190 @@ var searchCmd = &cobra.Command{
 }
 
 func init() {
-	searchCmd.Flags().String("context", "", "Context description for semantic search")
+	searchCmd.Flags().String("context", "", "Context description to search for")
+	searchCmd.Flags().String("type", "", "Only return results of this type (node, edge or graph)")
+	searchCmd.Flags().String("graph", "", "Only search the graph with this title")
+	searchCmd.Flags().Bool("remote", false, "Search the registry instead of local graphs")
+	searchCmd.Flags().Bool("all", false, "Search local graphs and the registry and merge the results")
+	searchCmd.Flags().Bool("public", false, "Only return public registry graphs (lists them when no context is given)")
+	searchCmd.Flags().String("owner", "", "Only return registry graphs owned by this user ID ('me' for yourself)")
+	searchCmd.Flags().Int("limit", search.DefaultLimit, "Maximum number of registry graphs to return")
+	searchCmd.Flags().Int("offset", 0, "Number of registry graphs to skip")
 	rootCmd.AddCommand(searchCmd)
 }
 
-func searchGraphs(context string) error {
+func searchGraphs(context string, opts search.Options) error {
 	// Check if .tribal exists
 	if _, err := os.Stat(".tribal"); os.IsNotExist(err) {
 		return fmt.Errorf("not a tribal repository. Run 'tribal init' first")
 	}
 
-	graphsDir := filepath.Join(".tribal", "graphs")
-	if _, err := os.Stat(graphsDir); os.IsNotExist(err) {
-		fmt.Println("No graphs found.")
-		return nil
+	fmt.Printf("Searching for matches: %s\n\n", context)
+
+	results, err := search.Local(context, opts)
+	if err != nil {
+		return err
 	}
 
-	// Read all graph files
-	files, err := ioutil.ReadDir(graphsDir)
+	printSearchResults(results, false)
+	return nil
+}
+
+func searchRemote(context string, opts search.Options, remoteOpts search.RemoteOptions, owner string) error {
+	fmt.Printf("Searching registry for matches: %s\n\n", context)
+
+	graphs, total, err := fetchRemoteGraphs(context, remoteOpts, owner)
 	if err != nil {
-		return fmt.Errorf("failed to read graphs directory: %w", err)
+		return err
 	}
 
-	fmt.Printf("Searching for graphs matching: %s\n\n", context)
+	results := search.RemoteResults(graphs, context, opts)
+	printSearchResults(results, true)
 
-	contextLower := strings.ToLower(context)
-	matches := []map[string]interface{}{}
+	if total > remoteOpts.Offset+len(graphs) {
+		fmt.Printf("Showing %d of %d registry graphs. Use --offset %d for more.\n",
+			len(graphs), total, remoteOpts.Offset+len(graphs))
+	}
 
-	for _, file := range files {
-		if !strings.HasSuffix(file.Name(), ".json") {
-			continue
-		}
+	return nil
+}
 
-		graphPath := filepath.Join(graphsDir, file.Name())
-		graphData, err := ioutil.ReadFile(graphPath)
-		if err != nil {
-			continue
-		}
+func searchAll(context string, opts search.Options, remoteOpts search.RemoteOptions, owner string) error {
+	// Check if .tribal exists
+	if _, err := os.Stat(".tribal"); os.IsNotExist(err) {
+		return fmt.Errorf("not a tribal repository. Run 'tribal init' first")
+	}
 
-		var graph map[string]interface{}
-		if err := json.Unmarshal(graphData, &graph); err != nil {
-			continue
-		}
+	files, err := graph.LoadAll()
+	if err != nil {
+		return err
+	}
 
-		title, _ := graph["title"].(string)
-		titleLower := strings.ToLower(title)
+	fmt.Printf("Searching local graphs and registry for matches: %s\n\n", context)
 
-		// Simple keyword matching (could be enhanced with proper semantic search)
-		score := 0
-		contextWords := strings.Fields(contextLower)
-		titleWords := strings.Fields(titleLower)
+	var local []search.Result
+	if context != "" {
+		local = search.Files(files, context, opts)
+	}
 
-		for _, contextWord := range contextWords {
-			for _, titleWord := range titleWords {
-				if strings.Contains(titleWord, contextWord) || strings.Contains(contextWord, titleWord) {
-					score++
-				}
-			}
-		}
+	var remote []search.Result
+	graphs, _, err := fetchRemoteGraphs(context, remoteOpts, owner)
+	if err != nil {
+		fmt.Printf("Warning: %v\nShowing local results only.\n\n", err)
+	} else {
+		remote = search.RemoteResults(graphs, context, opts)
+	}
+	if err := search.CheckGraph(files, opts.Graph); err != nil && !hasRemoteGraph(graphs, opts.Graph) {
+		return err
+	}
 
-		// Check metadata for additional context
-		if metadata, ok := graph["metadata"].(map[string]interface{}); ok {
-			if description, exists := metadata["description"].(string); exists {
-				descLower := strings.ToLower(description)
-				for _, contextWord := range contextWords {
-					if strings.Contains(descLower, contextWord) {
-						score += 2 // Weight description matches higher
-					}
-				}
-			}
-		}
+	printSearchResults(search.Merge(local, files, remote), true)
+	return nil
+}
 
-		if score > 0 {
-			graph["_score"] = score
-			graph["_filename"] = file.Name()
-			matches = append(matches, graph)
+// hasRemoteGraph reports whether a registry graph has the given title.
+func hasRemoteGraph(graphs []client.Graph, title string) bool {
+	for _, g := range graphs {
+		if strings.EqualFold(g.Title, title) {
+			return true
 		}
 	}
+	return false
+}
+
+// fetchRemoteGraphs runs a registry search using the repository's registry
+// URL and credentials, or the defaults outside a repository.
+func fetchRemoteGraphs(context string, opts search.RemoteOptions, owner string) ([]client.Graph, int, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		cfg = config.CreateDefaultConfig()
+	}
 
-	if len(matches) == 0 {
-		fmt.Println("No matching graphs found.")
-		return nil
+	c := client.NewClient(cfg.RegistryURL)
+	if err := c.ValidateURL(); err != nil {
+		return nil, 0, fmt.Errorf("invalid registry URL: %w", err)
+	}
+	if cfg.IsAuthenticated() {
+		c.SetToken(cfg.Token)
 	}
 
-	// Sort by score (simple bubble sort for now)
-	for i := 0; i < len(matches)-1; i++ {
-		for j := 0; j < len(matches)-i-1; j++ {
-			scoreA, _ := matches[j]["_score"].(int)
-			scoreB, _ := matches[j+1]["_score"].(int)
-			if scoreA < scoreB {
-				matches[j], matches[j+1] = matches[j+1], matches[j]
+	if owner != "" {
+		if owner == "me" {
+			owner = cfg.UserID
+			if owner == "" && cfg.IsAuthenticated() {
+				// A token from TRIBAL_TOKEN comes without the user's ID
+				user, err := c.GetMe()
+				if err != nil {
+					return nil, 0, fmt.Errorf("failed to look up the logged-in user: %w", err)
+				}
+				owner = user.ID.String()
+			}
+			if owner == "" {
+				return nil, 0, fmt.Errorf("--owner me requires 'tribal login'")
 			}
 		}
+		id, err := uuid.Parse(owner)
+		if err != nil {
+			return nil, 0, fmt.Errorf("invalid owner ID %q: %w", owner, err)
+		}
+		opts.Owner = &id
 	}
 
-	// Display results
-	fmt.Printf("Found %d matching graphs:\n\n", len(matches))
-	for i, match := range matches {
-		title, _ := match["title"].(string)
-		score, _ := match["_score"].(int)
-		filename, _ := match["_filename"].(string)
+	return search.Remote(c, context, opts)
+}
 
-		fmt.Printf("%d. %s (score: %d)\n", i+1, title, score)
-		fmt.Printf("   File: %s\n", filename)
+func printSearchResults(results []search.Result, showSource bool) {
+	if len(results) == 0 {
+		fmt.Println("No matches found.")
+		return
+	}
 
-		if metadata, ok := match["metadata"].(map[string]interface{}); ok {
-			if description, exists := metadata["description"].(string); exists {
-				fmt.Printf("   Description: %s\n", description)
-			}
+	color := term.IsTerminal(int(os.Stdout.Fd()))
+
+	fmt.Printf("Found %d matches:\n\n", len(results))
+	for i, r := range results {
+		fmt.Printf("%d. [%s] %s (score: %d)", i+1, r.Kind, r.Label, r.Score)
+		if showSource {
+			fmt.Printf(" (%s)", r.Source)
+		}
+		fmt.Println()
+
+		if r.GraphFile != "" {
+			fmt.Printf("   Graph: %s (%s)\n", r.GraphTitle, r.GraphFile)
+		} else {
+			fmt.Printf("   Graph: %s\n", r.GraphTitle)
+		}
+		if r.GraphID != "" && r.Source != search.SourceLocal {
+			fmt.Printf("   Registry ID: %s\n", r.GraphID)
+		}
+		if r.ID != "" {
+			fmt.Printf("   ID: %s\n", r.ID)
+		}
+		if r.Snippet.Text != "" {
+			fmt.Printf("   %s\n", highlightSnippet(r.Snippet, color))
 		}
 		fmt.Println()
 	}
+}
 
-	return nil
-}
\ No newline at end of file
+// highlightSnippet marks matched words in bold on a terminal, or with
+// brackets when output is redirected.
+func highlightSnippet(s search.Snippet, color bool) string {
+	open, close := "[", "]"
+	if color {
+		open, close = "\033[1;33m", "\033[0m"
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, h := range s.Highlights {
+		b.WriteString(s.Text[last:h.Start])
+		b.WriteString(open)
+		b.WriteString(s.Text[h.Start:h.End])
+		b.WriteString(close)
+		last = h.End
+	}
+	b.WriteString(s.Text[last:])
+
+	return b.String()
+}
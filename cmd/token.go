@@ -0,0 +1,215 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/tribal/tribal-cli/internal/client"
+)
+
+var tokenCmd = &cobra.Command{
+	Use:   "token",
+	Short: "Manage personal access tokens",
+	Long: `Create, list and revoke personal access tokens on the registry you are
+logged in to. Tokens are meant for scripts and CI:
+
+  tribal token create ci --expires 90d
+  echo "$TRIBAL_PAT" | tribal login --token-stdin   # or export TRIBAL_TOKEN`,
+}
+
+var tokenCreateCmd = &cobra.Command{
+	Use:   "create <name>",
+	Short: "Create a personal access token",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		expires, _ := cmd.Flags().GetString("expires")
+		scopes, _ := cmd.Flags().GetStringSlice("scope")
+		quiet, _ := cmd.Flags().GetBool("quiet")
+		remoteName, _ := cmd.Flags().GetString("remote")
+
+		if err := createToken(args[0], expires, scopes, quiet, remoteName); err != nil {
+			fmt.Printf("Error creating token: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var tokenListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List personal access tokens",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		remoteName, _ := cmd.Flags().GetString("remote")
+		if err := listTokens(remoteName); err != nil {
+			fmt.Printf("Error listing tokens: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var tokenRevokeCmd = &cobra.Command{
+	Use:   "revoke <id|name>",
+	Short: "Revoke a personal access token",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		remoteName, _ := cmd.Flags().GetString("remote")
+		if err := revokeToken(args[0], remoteName); err != nil {
+			fmt.Printf("Error revoking token: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	tokenCreateCmd.Flags().String("expires", "", "Lifetime such as 30d or 720h, or an expiry date (YYYY-MM-DD)")
+	tokenCreateCmd.Flags().StringSlice("scope", nil, "Scopes granted to the token (default: the registry's default)")
+	tokenCreateCmd.Flags().BoolP("quiet", "q", false, "Print only the token")
+
+	for _, c := range []*cobra.Command{tokenCreateCmd, tokenListCmd, tokenRevokeCmd} {
+		c.Flags().String("remote", "", "Use the registry of this remote")
+		tokenCmd.AddCommand(c)
+	}
+	rootCmd.AddCommand(tokenCmd)
+}
+
+// tokenClient returns a client for the registry, authenticated with the
+// current login.
+func tokenClient(remoteName string) (*client.Client, error) {
+	cfg, _, err := loadAuthConfig(remoteName, "")
+	if err != nil {
+		return nil, err
+	}
+	if !cfg.IsAuthenticated() {
+		return nil, fmt.Errorf("not logged in to %s. Run 'tribal login' or set TRIBAL_TOKEN", cfg.RegistryURL)
+	}
+
+	c := client.NewClient(cfg.RegistryURL)
+	if err := c.ValidateURL(); err != nil {
+		return nil, fmt.Errorf("invalid registry URL: %w", err)
+	}
+	c.SetToken(cfg.Token)
+	return c, nil
+}
+
+func createToken(name, expires string, scopes []string, quiet bool, remoteName string) error {
+	req := client.CreateTokenRequest{Name: name, Scopes: scopes}
+	if expires != "" {
+		at, err := parseExpiry(expires, time.Now())
+		if err != nil {
+			return err
+		}
+		req.ExpiresAt = &at
+	}
+
+	c, err := tokenClient(remoteName)
+	if err != nil {
+		return err
+	}
+	token, err := c.CreateToken(req)
+	if err != nil {
+		return err
+	}
+
+	if quiet {
+		fmt.Println(token.Token)
+		return nil
+	}
+	fmt.Printf("Created token %s (%s)\n", token.Name, token.ID)
+	if token.ExpiresAt != nil {
+		fmt.Printf("Expires: %s\n", token.ExpiresAt.Format("2006-01-02"))
+	}
+	fmt.Printf("\n%s\n\n", token.Token)
+	fmt.Println("Copy the token now; it is not shown again.")
+	return nil
+}
+
+// parseExpiry reads a lifetime in days ("30d") or as a Go duration
+// ("720h"), or an expiry date.
+func parseExpiry(s string, now time.Time) (time.Time, error) {
+	if strings.HasSuffix(s, "d") {
+		if days, err := strconv.Atoi(strings.TrimSuffix(s, "d")); err == nil && days > 0 {
+			return now.AddDate(0, 0, days), nil
+		}
+	}
+	if d, err := time.ParseDuration(s); err == nil && d > 0 {
+		return now.Add(d), nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		if !t.After(now) {
+			return time.Time{}, fmt.Errorf("expiry date %s is in the past", s)
+		}
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --expires %q. Use a lifetime such as 30d or 720h, or a date (YYYY-MM-DD)", s)
+}
+
+func listTokens(remoteName string) error {
+	c, err := tokenClient(remoteName)
+	if err != nil {
+		return err
+	}
+	tokens, err := c.ListTokens()
+	if err != nil {
+		return err
+	}
+	if len(tokens) == 0 {
+		fmt.Println("No personal access tokens.")
+		return nil
+	}
+
+	date := func(t *time.Time) string {
+		if t == nil {
+			return "-"
+		}
+		return t.Format("2006-01-02")
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+	fmt.Fprintln(w, "ID\tNAME\tSCOPES\tCREATED\tEXPIRES\tLAST USED")
+	for _, t := range tokens {
+		scopes := strings.Join(t.Scopes, ",")
+		if scopes == "" {
+			scopes = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", t.ID, t.Name, scopes, date(&t.CreatedAt), date(t.ExpiresAt), date(t.LastUsedAt))
+	}
+	return nil
+}
+
+// revokeToken revokes a token by ID, or by name when exactly one token has
+// that name.
+func revokeToken(ref, remoteName string) error {
+	c, err := tokenClient(remoteName)
+	if err != nil {
+		return err
+	}
+	tokens, err := c.ListTokens()
+	if err != nil {
+		return err
+	}
+
+	var matches []client.PersonalAccessToken
+	for _, t := range tokens {
+		if t.ID.String() == ref || t.Name == ref {
+			matches = append(matches, t)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return fmt.Errorf("no token with ID or name %q", ref)
+	case 1:
+	default:
+		return fmt.Errorf("%d tokens are named %q. Revoke one by ID", len(matches), ref)
+	}
+
+	if err := c.RevokeToken(matches[0].ID.String()); err != nil {
+		return err
+	}
+	fmt.Printf("Revoked token %s (%s)\n", matches[0].Name, matches[0].ID)
+	return nil
+}
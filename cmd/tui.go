@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/tribal/tribal-cli/internal/config"
+	"github.com/tribal/tribal-cli/internal/graph"
+	"github.com/tribal/tribal-cli/internal/tui"
+)
+
+var tuiCmd = &cobra.Command{
+	Use:   "tui",
+	Short: "Browse and edit graphs in an interactive terminal UI",
+	Long: `Open an interactive terminal UI for the graphs in this repository.
+
+The UI lists every local graph. Opening one shows its nodes, the neighbors of
+the selected node and the node's rendered markup. Nodes and edges can be
+added, renamed, deleted and connected; markup is edited in $VISUAL or $EDITOR.
+Every change is written to the graph file immediately and can be undone with u.
+
+Press s to stage the open graph and C to commit it. Press ? for all keys.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if _, err := os.Stat(".tribal"); os.IsNotExist(err) {
+			fmt.Println("Error: not a tribal repository. Run 'tribal init' first")
+			os.Exit(1)
+		}
+
+		actions := tui.Actions{
+			Stage:  stageGraphFile,
+			Commit: commitGraph,
+		}
+		if err := tui.Run(actions); err != nil {
+			fmt.Printf("Error running tui: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(tuiCmd)
+}
+
+// stageGraphFile makes the graph stored at path the current graph and stages it.
+func stageGraphFile(path string) error {
+	g, err := graph.Load(path)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	cfg.CurrentGraph = g.Title
+	cfg.CurrentGraphFile = path
+	if err := cfg.Save(); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	return stageGraph()
+}
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/tribal/tribal-cli/internal/ascii"
+	"github.com/tribal/tribal-cli/internal/graph"
+	"golang.org/x/term"
+)
+
+// defaultViewWidth is used when stdout is not a terminal.
+const defaultViewWidth = 100
+
+var viewCmd = &cobra.Command{
+	Use:   "view [graph]",
+	Short: "Draw a graph as text in the terminal",
+	Long: `Draw the current graph (or the graph with the given title) as a
+box-and-arrow diagram. Directed edges end in an arrowhead and undirected
+edges are dashed; edge labels are written beside their edge, or listed below
+the diagram when there is no room.
+
+Use --focus to draw only the nodes within --depth edges of one node. The
+drawing is limited to --width columns (the terminal width by default); graphs
+that are too wide, or have more than --max-nodes nodes, are printed as an
+adjacency list instead.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		focus, _ := cmd.Flags().GetString("focus")
+		depth, _ := cmd.Flags().GetInt("depth")
+		width, _ := cmd.Flags().GetInt("width")
+		maxNodes, _ := cmd.Flags().GetInt("max-nodes")
+		plain, _ := cmd.Flags().GetBool("ascii")
+		list, _ := cmd.Flags().GetBool("list")
+
+		title := ""
+		if len(args) > 0 {
+			title = args[0]
+		}
+
+		opts := ascii.Options{Width: width, ASCII: plain}
+		if err := viewGraph(title, focus, depth, maxNodes, list, opts); err != nil {
+			fmt.Printf("Error viewing graph: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	viewCmd.Flags().String("focus", "", "Only draw the neighborhood of this node (ID or label)")
+	viewCmd.Flags().Int("depth", 1, "Number of hops around --focus to include")
+	viewCmd.Flags().Int("width", 0, "Maximum width in columns (default: terminal width)")
+	viewCmd.Flags().Int("max-nodes", 40, "Print an adjacency list for graphs with more nodes than this")
+	viewCmd.Flags().Bool("ascii", false, "Use plain ASCII instead of Unicode box drawing")
+	viewCmd.Flags().Bool("list", false, "Always print an adjacency list")
+	rootCmd.AddCommand(viewCmd)
+}
+
+func viewGraph(title, focus string, depth, maxNodes int, list bool, opts ascii.Options) error {
+	f, err := graph.Open(title)
+	if err != nil {
+		return err
+	}
+	g := f.Graph
+
+	if focus != "" {
+		n, err := g.Resolve(focus)
+		if err != nil {
+			return err
+		}
+		if depth < 0 {
+			return fmt.Errorf("depth must not be negative")
+		}
+		g = g.Neighborhood(n.ID, depth)
+		opts.Highlight = n.ID
+	}
+
+	if opts.Width <= 0 {
+		opts.Width = defaultViewWidth
+		if w, _, err := term.GetSize(int(os.Stdout.Fd())); err == nil && w > 0 {
+			opts.Width = w
+		}
+	}
+
+	fmt.Printf("%s (%d nodes, %d edges)\n\n", g.Title, len(g.Nodes), len(g.Edges))
+	if len(g.Nodes) == 0 {
+		fmt.Println("This graph has no nodes.")
+		return nil
+	}
+
+	if !list && len(g.Nodes) <= maxNodes {
+		drawing, err := ascii.Draw(g, opts)
+		if err == nil {
+			fmt.Print(drawing)
+			return nil
+		}
+		if err != ascii.ErrTooWide {
+			return err
+		}
+		fmt.Printf("Graph is too wide to draw in %d columns; showing an adjacency list.\n\n", opts.Width)
+	} else if !list {
+		fmt.Printf("Graph has more than %d nodes; showing an adjacency list.\n\n", maxNodes)
+	}
+
+	fmt.Print(ascii.List(g, opts))
+	return nil
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/tribal/tribal-cli/internal/anchor"
+	"github.com/tribal/tribal-cli/internal/graph"
+)
+
+var whereLine = regexp.MustCompile(`^(.+):(\d+)$`)
+
+var whereCmd = &cobra.Command{
+	Use:   "where <path[:line]>",
+	Short: "List the nodes linked to a file or directory",
+	Long: `List the nodes, across every local graph, with a code anchor in the given
+file or directory. With :line, only anchors covering that line (or the whole
+file) are listed. Anchors that no longer match the working tree are flagged.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := showWhere(userRef(args[0])); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(whereCmd)
+}
+
+func showWhere(ref string) error {
+	path, line := ref, 0
+	if _, err := os.Stat(ref); err != nil {
+		if m := whereLine.FindStringSubmatch(ref); m != nil {
+			path = m[1]
+			line, _ = strconv.Atoi(m[2])
+		}
+	}
+
+	rel, err := anchor.Rel(workTree(), path)
+	if err != nil {
+		return err
+	}
+
+	files, err := graph.LoadAll()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	found := 0
+	for _, f := range files {
+		for _, n := range f.Graph.Nodes {
+			for _, a := range n.Anchors {
+				if !anchor.Covers(a, rel, line) {
+					continue
+				}
+				found++
+				fmt.Fprintf(w, "%s\t%s (%s)\t%s", f.Graph.Title, n.Label, n.ID, anchor.String(a))
+				if err := anchor.Check(workTree(), a); err != nil {
+					fmt.Fprintf(w, "\t[%v]", err)
+				}
+				fmt.Fprintln(w)
+			}
+		}
+	}
+
+	if found == 0 {
+		fmt.Printf("No nodes are linked to %s\n", ref)
+		return nil
+	}
+	return w.Flush()
+}
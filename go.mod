@@ -6,11 +6,11 @@ require (
 	github.com/spf13/cobra v1.10.1
 	github.com/google/uuid v1.5.0
 	golang.org/x/crypto v0.17.0
+	golang.org/x/term v0.15.0
 )
 
 require (
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
 	github.com/spf13/pflag v1.0.9 // indirect
 	golang.org/x/sys v0.15.0 // indirect
-	golang.org/x/term v0.15.0 // indirect
 )
@@ -0,0 +1,128 @@
+// Package agents writes the instruction files read by coding assistants,
+// pointing them at TRIBAL.md and the tribal commands. Every file gets the
+// same instructions inside a managed block, so re-running 'tribal init'
+// updates them in place and leaves the rest of each file alone.
+package agents
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/tribal/tribal-cli/internal/managed"
+)
+
+// Agent describes where one assistant reads its instructions from.
+type Agent struct {
+	Name string
+	Path string
+	// Header starts the file when it is created, e.g. rule front matter.
+	Header string
+	// Prepend places the block at the top of an existing file instead of
+	// the end.
+	Prepend bool
+}
+
+// Known lists the supported assistants.
+var Known = []Agent{
+	{Name: "claude", Path: "CLAUDE.md", Prepend: true},
+	{Name: "codex", Path: "AGENTS.md", Prepend: true},
+	{Name: "cursor", Path: filepath.Join(".cursor", "rules", "tribal.mdc"),
+		Header: "---\ndescription: How to use the tribal knowledge graphs in this repository\nalwaysApply: true\n---\n"},
+	{Name: "copilot", Path: filepath.Join(".github", "copilot-instructions.md"), Prepend: true},
+	{Name: "gemini", Path: "GEMINI.md", Prepend: true},
+	{Name: "windsurf", Path: filepath.Join(".windsurf", "rules", "tribal.md"),
+		Header: "---\ntrigger: always_on\n---\n"},
+}
+
+// aliases map other names to a known assistant.
+var aliases = map[string]string{
+	"agents":         "codex",
+	"claude-code":    "claude",
+	"github-copilot": "copilot",
+}
+
+// Instructions is the text placed in every instruction file.
+const Instructions = `This repository is developed using the TRIBAL framework. Whenever planning or making changes to the codebase, be sure to first consult TRIBAL.md in the root repository for reference.
+
+Tribal keeps knowledge graphs of this codebase under .tribal/graphs: nodes are components and concepts with markdown notes, edges are the relationships between them.
+
+- Before starting a task, run ` + "`tribal context \"<task description>\"`" + ` and read the briefing it prints.
+- Use ` + "`tribal search --context \"<words>\"`" + ` and ` + "`tribal where <path>`" + ` to find what is known about a feature or file.
+- When you add or change components, update the graph (` + "`tribal tui`" + `, or the ` + "`tribal mcp`" + ` server's tools) and check ` + "`tribal drift`" + `.
+- Commit graph changes with ` + "`tribal add -A`" + ` and ` + "`tribal commit -m \"<message>\"`" + `.`
+
+// legacy is the paragraph earlier versions of 'tribal init' prepended to
+// CLAUDE.md without markers. It is replaced by the managed block.
+const legacy = "This repository is developed using the TRIBAL framework. Whenever planning or making changes to the codebase, be sure to first consult TRIBAL.md in the root repository for reference.\n\n"
+
+// Names returns the names accepted by Parse.
+func Names() []string {
+	var names []string
+	for _, a := range Known {
+		names = append(names, a.Name)
+	}
+	return names
+}
+
+// Parse resolves a list of assistant names. "all" selects every known
+// assistant. Duplicates are dropped.
+func Parse(names []string) ([]Agent, error) {
+	var selected []Agent
+	seen := make(map[string]bool)
+	add := func(a Agent) {
+		if !seen[a.Name] {
+			seen[a.Name] = true
+			selected = append(selected, a)
+		}
+	}
+
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		if name == "all" {
+			for _, a := range Known {
+				add(a)
+			}
+			continue
+		}
+		if alias, ok := aliases[name]; ok {
+			name = alias
+		}
+		a, ok := find(name)
+		if !ok {
+			known := append(Names(), "all")
+			sort.Strings(known)
+			return nil, fmt.Errorf("unknown agent %q. Use one of: %s", name, strings.Join(known, ", "))
+		}
+		add(a)
+	}
+	return selected, nil
+}
+
+func find(name string) (Agent, bool) {
+	for _, a := range Known {
+		if a.Name == name {
+			return a, true
+		}
+	}
+	return Agent{}, false
+}
+
+// Render returns the content of the agent's file with the instructions
+// block added or updated, given its current content ("" if missing).
+func (a Agent) Render(existing string) string {
+	content := existing
+	if !managed.Has(content, managed.MarkdownMarkers) {
+		for strings.HasPrefix(content, legacy) {
+			content = strings.TrimPrefix(content, legacy)
+		}
+	}
+	if strings.TrimSpace(content) == "" {
+		content = a.Header
+	}
+	return managed.Apply(content, Instructions, managed.MarkdownMarkers, a.Prepend)
+}
@@ -0,0 +1,180 @@
+// Package anchor links graph nodes to files, line ranges and Go symbols in
+// the working tree.
+package anchor
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/tribal/tribal-cli/internal/client"
+)
+
+var (
+	lineSuffix  = regexp.MustCompile(`^(.+):(\d+)(?:-(\d+))?$`)
+	symbolShape = regexp.MustCompile(`^[A-Za-z_]\w*(\.[A-Za-z_]\w*){1,2}$`)
+)
+
+// Resolve turns a reference given on the command line into an anchor. The
+// reference is a path relative to the current directory, optionally
+// followed by :line or :start-end, or a Go symbol written as pkg.Name or
+// pkg.Type.Method where pkg is a package name, a directory or an import
+// path. The result is validated against the working tree under root.
+func Resolve(root, ref string) (client.Anchor, error) {
+	path, start, end := ref, 0, 0
+	if m := lineSuffix.FindStringSubmatch(ref); m != nil {
+		path = m[1]
+		start, _ = strconv.Atoi(m[2])
+		end = start
+		if m[3] != "" {
+			end, _ = strconv.Atoi(m[3])
+		}
+	}
+
+	if _, err := os.Stat(path); err == nil {
+		rel, err := Rel(root, path)
+		if err != nil {
+			return client.Anchor{}, err
+		}
+		a := client.Anchor{Path: rel, StartLine: start, EndLine: end}
+		if err := Check(root, a); err != nil {
+			return client.Anchor{}, err
+		}
+		return a, nil
+	}
+
+	if start == 0 && symbolShape.MatchString(ref[strings.LastIndex(ref, "/")+1:]) {
+		return FindSymbol(root, ref)
+	}
+	return client.Anchor{}, fmt.Errorf("no file or Go symbol named %s", ref)
+}
+
+// Rel converts a path relative to the current directory into a
+// slash-separated path relative to root. Paths outside root are rejected.
+func Rel(root, path string) (string, error) {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return "", err
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(absRoot, absPath)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s is outside the repository", path)
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// Check reports whether an anchor still points at something in the working
+// tree: the file must exist, the line range must lie within it and a symbol
+// must still be declared in it.
+func Check(root string, a client.Anchor) error {
+	full := filepath.Join(root, filepath.FromSlash(a.Path))
+	info, err := os.Stat(full)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("%s does not exist", a.Path)
+	}
+	if err != nil {
+		return err
+	}
+
+	if a.Package && !info.IsDir() {
+		return fmt.Errorf("%s is not a package directory", a.Path)
+	}
+	if a.StartLine != 0 || a.EndLine != 0 {
+		if info.IsDir() {
+			return fmt.Errorf("%s is a directory and cannot have a line range", a.Path)
+		}
+		if a.StartLine < 1 || a.EndLine < a.StartLine {
+			return fmt.Errorf("invalid line range %d-%d", a.StartLine, a.EndLine)
+		}
+		lines, err := countLines(full)
+		if err != nil {
+			return err
+		}
+		if a.EndLine > lines {
+			return fmt.Errorf("line %d is past the end of %s (%d lines)", a.EndLine, a.Path, lines)
+		}
+	}
+
+	if a.Symbol != "" {
+		if _, ok := declaration(full, symbolName(a.Symbol)); !ok {
+			return fmt.Errorf("%s is no longer declared in %s", a.Symbol, a.Path)
+		}
+	}
+	return nil
+}
+
+// Covers reports whether the anchor refers to path, to something inside it
+// or to a directory containing it. Package anchors only contain the Go files
+// directly inside their directory. A non-zero line must also fall in the
+// anchor's line range when it has one.
+func Covers(a client.Anchor, path string, line int) bool {
+	path = strings.TrimSuffix(path, "/")
+	contains := strings.HasPrefix(path, a.Path+"/")
+	if a.Package {
+		contains = inPackage(a.Path, path)
+	}
+	if path != "." && path != "" && a.Path != path && !strings.HasPrefix(a.Path, path+"/") && !contains {
+		return false
+	}
+	if line == 0 || a.StartLine == 0 {
+		return true
+	}
+	return line >= a.StartLine && line <= a.EndLine
+}
+
+// String formats an anchor as path[:start[-end]], preceded by its symbol.
+// Package anchors are marked as such.
+func String(a client.Anchor) string {
+	loc := a.Path
+	switch {
+	case a.Package:
+		loc += " (Go package)"
+	case a.StartLine == 0:
+	case a.EndLine > a.StartLine:
+		loc += fmt.Sprintf(":%d-%d", a.StartLine, a.EndLine)
+	default:
+		loc += fmt.Sprintf(":%d", a.StartLine)
+	}
+	if a.Symbol != "" {
+		return a.Symbol + " (" + loc + ")"
+	}
+	return loc
+}
+
+// Equal reports whether two anchors point at the same location.
+func Equal(a, b client.Anchor) bool {
+	return a.Path == b.Path && a.StartLine == b.StartLine && a.EndLine == b.EndLine && a.Symbol == b.Symbol && a.Package == b.Package
+}
+
+// inPackage reports whether path is a Go file directly inside dir.
+func inPackage(dir, path string) bool {
+	parent := "."
+	if i := strings.LastIndex(path, "/"); i >= 0 {
+		parent = path[:i]
+	}
+	return parent == dir && strings.HasSuffix(path, ".go")
+}
+
+func countLines(path string) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	lines := 0
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		lines++
+	}
+	return lines, scanner.Err()
+}
@@ -0,0 +1,313 @@
+package anchor
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/tribal/tribal-cli/internal/client"
+	"github.com/tribal/tribal-cli/internal/git"
+)
+
+// Status classifies how an anchor compares with the working tree.
+type Status string
+
+const (
+	StatusOK         Status = "ok"
+	StatusChanged    Status = "changed"
+	StatusMoved      Status = "moved"
+	StatusDeleted    Status = "deleted"
+	StatusUnrecorded Status = "unrecorded"
+)
+
+// Drift is the result of comparing an anchor with the working tree.
+type Drift struct {
+	Anchor client.Anchor
+	Status Status
+	// Current is where the anchored content is now, with its current hash.
+	// It equals Anchor apart from the hash unless the content moved.
+	Current client.Anchor
+	// Detail explains the status in a few words.
+	Detail string
+	// Commits lists the git commits that touched the file since the anchor
+	// was recorded, when git history is available.
+	Commits []git.Commit
+}
+
+// Stale reports whether the drift should fail a check.
+func (d Drift) Stale() bool {
+	return d.Status == StatusChanged || d.Status == StatusMoved || d.Status == StatusDeleted
+}
+
+// Record fingerprints the content an anchor points at and notes the git
+// commit it was recorded against.
+func Record(root string, a *client.Anchor) error {
+	hash, err := Fingerprint(root, *a)
+	if err != nil {
+		return err
+	}
+	a.Hash = hash
+	a.Commit = ""
+	if git.Available(root) {
+		if head, err := git.Head(root); err == nil {
+			a.Commit = head
+		}
+	}
+	return nil
+}
+
+// Fingerprint hashes the content an anchor points at: the declaration of a
+// symbol, the lines of a line range, a whole file, every file under a
+// directory, or the Go files of a package.
+func Fingerprint(root string, a client.Anchor) (string, error) {
+	full := filepath.Join(root, filepath.FromSlash(a.Path))
+
+	if a.Symbol != "" {
+		d, ok := declaration(full, symbolName(a.Symbol))
+		if !ok {
+			return "", fmt.Errorf("%s is not declared in %s", a.Symbol, a.Path)
+		}
+		return hash(d.text), nil
+	}
+
+	info, err := os.Stat(full)
+	if err != nil {
+		return "", err
+	}
+	if info.IsDir() && a.Package {
+		return hashPackage(full)
+	}
+	if info.IsDir() {
+		return hashDir(full)
+	}
+
+	data, err := os.ReadFile(full)
+	if err != nil {
+		return "", err
+	}
+	if a.StartLine == 0 {
+		return hash(data), nil
+	}
+
+	lines := splitLines(data)
+	if a.EndLine > len(lines) {
+		return "", fmt.Errorf("line %d is past the end of %s (%d lines)", a.EndLine, a.Path, len(lines))
+	}
+	return hash(bytes.Join(lines[a.StartLine-1:a.EndLine], []byte("\n"))), nil
+}
+
+// Detect compares an anchor with the working tree. Symbols are looked for
+// elsewhere in the tree when they leave their file, line ranges are looked
+// for elsewhere in their file, and git is asked about renamed files.
+func Detect(root string, a client.Anchor) Drift {
+	d := detect(root, a)
+	if d.Stale() && a.Commit != "" && git.Available(root) {
+		path := a.Path
+		if a.Package {
+			// Only commits to the package's own Go files.
+			path = ":(glob)" + strings.TrimPrefix(a.Path+"/*.go", "./")
+		}
+		d.Commits, _ = git.Log(root, a.Commit, path)
+	}
+	return d
+}
+
+func detect(root string, a client.Anchor) Drift {
+	d := Drift{Anchor: a, Current: a}
+	if err := Check(root, a); err != nil {
+		if a.Symbol != "" {
+			return d.symbolMoved(root)
+		}
+		if a.Commit != "" && git.Available(root) {
+			if renamed, _ := git.Renamed(root, a.Commit, a.Path); renamed != "" {
+				return d.fileMoved(root, renamed)
+			}
+		}
+		d.Status, d.Detail = StatusDeleted, err.Error()
+		return d
+	}
+
+	current, err := Fingerprint(root, a)
+	if err != nil {
+		d.Status, d.Detail = StatusDeleted, err.Error()
+		return d
+	}
+	d.Current.Hash = current
+
+	switch {
+	case a.Hash == "":
+		d.Status, d.Detail = StatusUnrecorded, "no content hash recorded; run 'tribal drift --update'"
+	case a.Hash == current:
+		d.Status = StatusOK
+		if a.Symbol != "" {
+			// A symbol that shifted within its file is still the same symbol.
+			d.Current = d.locateSymbol(root, a.Path)
+		}
+	case a.Symbol == "" && a.StartLine != 0:
+		if moved, ok := findLines(root, a, a.Path); ok {
+			d.Status, d.Current = StatusMoved, moved
+			d.Detail = fmt.Sprintf("lines moved to %d-%d", moved.StartLine, moved.EndLine)
+			return d
+		}
+		d.Status, d.Detail = StatusChanged, "content of lines changed"
+	case a.Symbol != "":
+		d.Status, d.Detail = StatusChanged, "declaration changed"
+		d.Current = d.locateSymbol(root, a.Path)
+	default:
+		d.Status, d.Detail = StatusChanged, "content changed"
+	}
+	return d
+}
+
+// symbolMoved looks for a symbol that is no longer declared in its file.
+func (d Drift) symbolMoved(root string) Drift {
+	found, err := FindSymbol(root, d.Anchor.Symbol)
+	if err != nil {
+		d.Status, d.Detail = StatusDeleted, err.Error()
+		return d
+	}
+
+	found.Hash, _ = Fingerprint(root, found)
+	d.Status, d.Current = StatusMoved, found
+	d.Detail = "declaration moved to " + found.Path
+	if d.Anchor.Hash != "" && found.Hash != d.Anchor.Hash {
+		d.Detail += " and changed"
+	}
+	return d
+}
+
+// fileMoved follows a file git reports as renamed.
+func (d Drift) fileMoved(root, renamed string) Drift {
+	d.Status = StatusMoved
+	d.Detail = "file renamed to " + renamed
+
+	moved := d.Anchor
+	moved.Path = renamed
+	if d.Anchor.StartLine != 0 {
+		if found, ok := findLines(root, d.Anchor, renamed); ok {
+			moved = found
+		} else {
+			d.Detail += " and lines changed"
+		}
+	}
+	if hash, err := Fingerprint(root, moved); err == nil {
+		if d.Anchor.StartLine == 0 && d.Anchor.Hash != "" && hash != d.Anchor.Hash {
+			d.Detail += " and changed"
+		}
+		moved.Hash = hash
+	}
+	d.Current = moved
+	return d
+}
+
+// locateSymbol returns the anchor with the symbol's current line range.
+func (d Drift) locateSymbol(root, path string) client.Anchor {
+	current := d.Current
+	if found, ok := declaration(filepath.Join(root, filepath.FromSlash(path)), symbolName(d.Anchor.Symbol)); ok {
+		current.StartLine, current.EndLine = found.startLine, found.endLine
+	}
+	return current
+}
+
+// findLines searches path for a block of lines matching the anchor's hash.
+// It only succeeds when the block occurs exactly once.
+func findLines(root string, a client.Anchor, path string) (client.Anchor, bool) {
+	data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(path)))
+	if err != nil || a.Hash == "" {
+		return client.Anchor{}, false
+	}
+
+	lines := splitLines(data)
+	n := a.EndLine - a.StartLine + 1
+	var matches []int
+	for start := 0; start+n <= len(lines); start++ {
+		if hash(bytes.Join(lines[start:start+n], []byte("\n"))) == a.Hash {
+			matches = append(matches, start+1)
+		}
+	}
+	if len(matches) != 1 {
+		return client.Anchor{}, false
+	}
+
+	moved := a
+	moved.Path = path
+	moved.StartLine, moved.EndLine = matches[0], matches[0]+n-1
+	return moved, true
+}
+
+func hashDir(dir string) (string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && path != dir && strings.HasPrefix(d.Name(), ".") {
+			return filepath.SkipDir
+		}
+		if d.Type().IsRegular() {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	sort.Strings(files)
+
+	h := sha256.New()
+	for _, path := range files {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		rel, _ := filepath.Rel(dir, path)
+		fmt.Fprintf(h, "%s %s\n", filepath.ToSlash(rel), hash(data))
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// hashPackage hashes the Go files directly inside dir, leaving out
+// subdirectories, which hold packages of their own, and other files.
+func hashPackage(dir string) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	for _, e := range entries {
+		if !e.Type().IsRegular() || !strings.HasSuffix(e.Name(), ".go") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(h, "%s %s\n", e.Name(), hash(data))
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func hash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// splitLines splits content into lines without their terminators, so CRLF
+// and LF files hash alike.
+func splitLines(data []byte) [][]byte {
+	lines := bytes.Split(data, []byte("\n"))
+	if len(lines) > 0 && len(lines[len(lines)-1]) == 0 {
+		lines = lines[:len(lines)-1]
+	}
+	for i, l := range lines {
+		lines[i] = bytes.TrimSuffix(l, []byte("\r"))
+	}
+	return lines
+}
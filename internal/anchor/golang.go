@@ -0,0 +1,214 @@
+package anchor
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/tribal/tribal-cli/internal/client"
+)
+
+var moduleLine = regexp.MustCompile(`(?m)^module\s+(\S+)`)
+
+// FindSymbol locates a top-level Go declaration under root. ref is
+// pkg.Name or pkg.Type.Method, where pkg is a package name, a directory
+// relative to root or a full import path.
+func FindSymbol(root, ref string) (client.Anchor, error) {
+	slash := strings.LastIndex(ref, "/")
+	dot := strings.Index(ref[slash+1:], ".")
+	if dot < 0 {
+		return client.Anchor{}, fmt.Errorf("%s is not a Go symbol", ref)
+	}
+	pkg, name := ref[:slash+1+dot], ref[slash+2+dot:]
+	module := ModulePath(root)
+
+	var found []client.Anchor
+	err := WalkGoFiles(root, func(rel, pkgName string) error {
+		dir := filepath.ToSlash(filepath.Dir(rel))
+		if pkg != pkgName && pkg != dir && (module == "" || pkg != ImportPath(module, dir)) {
+			return nil
+		}
+		if d, ok := declaration(filepath.Join(root, rel), name); ok {
+			found = append(found, client.Anchor{Path: rel, StartLine: d.startLine, EndLine: d.endLine, Symbol: pkgName + "." + name})
+		}
+		return nil
+	})
+	if err != nil {
+		return client.Anchor{}, err
+	}
+
+	switch len(found) {
+	case 0:
+		return client.Anchor{}, fmt.Errorf("no Go declaration %s found", ref)
+	case 1:
+		return found[0], nil
+	}
+	var paths []string
+	for _, a := range found {
+		paths = append(paths, a.Path)
+	}
+	return client.Anchor{}, fmt.Errorf("%s is ambiguous, declared in %s. Qualify the package with its directory", ref, strings.Join(paths, ", "))
+}
+
+// ModulePath returns the module path declared in root/go.mod, or "".
+func ModulePath(root string) string {
+	data, err := os.ReadFile(filepath.Join(root, "go.mod"))
+	if err != nil {
+		return ""
+	}
+	if m := moduleLine.FindSubmatch(data); m != nil {
+		return string(m[1])
+	}
+	return ""
+}
+
+// ImportPath joins a module path and a slash-separated package directory.
+func ImportPath(module, dir string) string {
+	if dir == "." || dir == "" {
+		return module
+	}
+	return module + "/" + dir
+}
+
+// WalkGoFiles calls fn for every non-test Go file under root, in lexical
+// order, with its slash-separated path relative to root and its package
+// name. Hidden directories, vendor, testdata and node_modules are skipped.
+func WalkGoFiles(root string, fn func(rel, pkgName string) error) error {
+	fset := token.NewFileSet()
+	var files []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			name := d.Name()
+			if path != root && (strings.HasPrefix(name, ".") || name == "vendor" || name == "testdata" || name == "node_modules") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.HasSuffix(path, ".go") && !strings.HasSuffix(path, "_test.go") {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	sort.Strings(files)
+
+	for _, path := range files {
+		f, err := parser.ParseFile(fset, path, nil, parser.PackageClauseOnly)
+		if err != nil {
+			continue
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		if err := fn(filepath.ToSlash(rel), f.Name.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decl is the location and source text of a top-level Go declaration.
+type decl struct {
+	startLine int
+	endLine   int
+	text      []byte
+}
+
+// declaration finds a top-level declaration in a Go file. name is Name for
+// functions, types, variables and constants, or Type.Method for methods.
+func declaration(path, name string) (decl, bool) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return decl{}, false
+	}
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, path, src, parser.SkipObjectResolution)
+	if err != nil {
+		return decl{}, false
+	}
+
+	receiver, method := "", name
+	if i := strings.Index(name, "."); i >= 0 {
+		receiver, method = name[:i], name[i+1:]
+	}
+	span := func(from, to token.Pos) (decl, bool) {
+		start, end := fset.Position(from), fset.Position(to)
+		return decl{startLine: start.Line, endLine: end.Line, text: src[start.Offset:end.Offset]}, true
+	}
+
+	for _, d := range f.Decls {
+		switch d := d.(type) {
+		case *ast.FuncDecl:
+			if d.Name.Name == method && ReceiverType(d) == receiver {
+				return span(d.Pos(), d.End())
+			}
+		case *ast.GenDecl:
+			if receiver != "" {
+				continue
+			}
+			for _, spec := range d.Specs {
+				var names []*ast.Ident
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					names = []*ast.Ident{s.Name}
+				case *ast.ValueSpec:
+					names = s.Names
+				}
+				for _, ident := range names {
+					if ident.Name != name {
+						continue
+					}
+					if d.Lparen.IsValid() {
+						return span(spec.Pos(), spec.End())
+					}
+					return span(d.Pos(), d.End())
+				}
+			}
+		}
+	}
+	return decl{}, false
+}
+
+// ReceiverType returns the receiver type name of a method, without pointer
+// or type parameters, or "" for plain functions.
+func ReceiverType(fn *ast.FuncDecl) string {
+	if fn.Recv == nil || len(fn.Recv.List) == 0 {
+		return ""
+	}
+	t := fn.Recv.List[0].Type
+	for {
+		switch x := t.(type) {
+		case *ast.StarExpr:
+			t = x.X
+		case *ast.IndexExpr:
+			t = x.X
+		case *ast.IndexListExpr:
+			t = x.X
+		case *ast.Ident:
+			return x.Name
+		default:
+			return ""
+		}
+	}
+}
+
+// symbolName strips the package from a pkg.Name or pkg.Type.Method symbol.
+func symbolName(symbol string) string {
+	if i := strings.Index(symbol, "."); i >= 0 {
+		return symbol[i+1:]
+	}
+	return symbol
+}
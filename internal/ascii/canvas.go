@@ -0,0 +1,216 @@
+package ascii
+
+import "strings"
+
+// Directions a line leaves a cell in, combined to pick a box-drawing rune.
+const (
+	up uint8 = 1 << iota
+	down
+	left
+	right
+)
+
+type cell struct {
+	lines  uint8
+	dashed uint8 // the subset of lines drawn while the canvas was dashing
+	text   rune  // set for labels, arrowheads and other literal runes
+	double bool  // draw lines with the double-line set
+}
+
+// canvas is a grid of cells that lines and text are drawn onto.
+type canvas struct {
+	cells [][]cell
+	width int
+	ascii bool
+	// dashing marks the lines drawn while it is set as dashed.
+	dashing bool
+}
+
+func newCanvas(width, height int, ascii bool) *canvas {
+	c := &canvas{width: width, ascii: ascii}
+	c.cells = make([][]cell, height)
+	for y := range c.cells {
+		c.cells[y] = make([]cell, width)
+	}
+	return c
+}
+
+func (c *canvas) at(x, y int) *cell {
+	if y < 0 || y >= len(c.cells) || x < 0 || x >= c.width {
+		return nil
+	}
+	return &c.cells[y][x]
+}
+
+func (c *canvas) empty(x, y int) bool {
+	cl := c.at(x, y)
+	return cl != nil && cl.lines == 0 && cl.text == 0
+}
+
+// line draws a horizontal or vertical line between two points, inclusive.
+func (c *canvas) line(x1, y1, x2, y2 int) {
+	if x1 == x2 {
+		if y1 > y2 {
+			y1, y2 = y2, y1
+		}
+		for y := y1; y <= y2; y++ {
+			if cl := c.at(x1, y); cl != nil {
+				if y > y1 {
+					c.draw(cl, up)
+				}
+				if y < y2 {
+					c.draw(cl, down)
+				}
+			}
+		}
+		return
+	}
+
+	if x1 > x2 {
+		x1, x2 = x2, x1
+	}
+	for x := x1; x <= x2; x++ {
+		if cl := c.at(x, y1); cl != nil {
+			if x > x1 {
+				c.draw(cl, left)
+			}
+			if x < x2 {
+				c.draw(cl, right)
+			}
+		}
+	}
+}
+
+func (c *canvas) draw(cl *cell, dir uint8) {
+	cl.lines |= dir
+	if c.dashing {
+		cl.dashed |= dir
+	}
+}
+
+// path draws connected line segments through the given points.
+func (c *canvas) path(points ...[2]int) {
+	for i := 1; i < len(points); i++ {
+		a, b := points[i-1], points[i]
+		if a[0] != b[0] && a[1] != b[1] {
+			c.line(a[0], a[1], b[0], a[1])
+			c.line(b[0], a[1], b[0], b[1])
+			continue
+		}
+		c.line(a[0], a[1], b[0], b[1])
+	}
+}
+
+func (c *canvas) box(x, y, w, h int, double bool) {
+	c.path([2]int{x, y}, [2]int{x + w - 1, y}, [2]int{x + w - 1, y + h - 1}, [2]int{x, y + h - 1}, [2]int{x, y})
+	if double {
+		for i := x; i < x+w; i++ {
+			c.at(i, y).double = true
+			c.at(i, y+h-1).double = true
+		}
+		for j := y; j < y+h; j++ {
+			c.at(x, j).double = true
+			c.at(x+w-1, j).double = true
+		}
+	}
+}
+
+func (c *canvas) text(x, y int, s string) {
+	for _, r := range s {
+		if cl := c.at(x, y); cl != nil {
+			cl.text = r
+		}
+		x++
+	}
+}
+
+// fits reports whether s can be written at x, y without touching anything
+// already drawn, keeping a blank cell on either side.
+func (c *canvas) fits(x, y int, s string) bool {
+	n := len([]rune(s))
+	for i := x - 1; i <= x+n; i++ {
+		if !c.empty(i, y) {
+			return false
+		}
+	}
+	return true
+}
+
+var (
+	unicodeRunes = map[uint8]rune{
+		up: '│', down: '│', up | down: '│',
+		left: '─', right: '─', left | right: '─',
+		down | right: '┌', down | left: '┐', up | right: '└', up | left: '┘',
+		up | down | right: '├', up | down | left: '┤',
+		down | left | right: '┬', up | left | right: '┴',
+		up | down | left | right: '┼',
+	}
+	doubleRunes = map[uint8]rune{
+		up | down: '║', left | right: '═',
+		down | right: '╔', down | left: '╗', up | right: '╚', up | left: '╝',
+		down | left | right: '╤', up | left | right: '╧',
+	}
+)
+
+func (c *canvas) rune(cl cell) rune {
+	if cl.text != 0 {
+		return cl.text
+	}
+	if cl.lines == 0 {
+		return ' '
+	}
+
+	// Straight runs of dashed lines are drawn dashed; corners and crossings
+	// keep the solid runes.
+	dashed := cl.dashed == cl.lines && !cl.double
+
+	if c.ascii {
+		switch cl.lines {
+		case up, down, up | down:
+			if dashed {
+				return ':'
+			}
+			return '|'
+		case left, right, left | right:
+			if dashed {
+				return '.'
+			}
+			if cl.double {
+				return '='
+			}
+			return '-'
+		}
+		if cl.double {
+			return '#'
+		}
+		return '+'
+	}
+
+	if cl.double {
+		if r, ok := doubleRunes[cl.lines]; ok {
+			return r
+		}
+	}
+	if dashed {
+		switch cl.lines {
+		case up, down, up | down:
+			return '┆'
+		case left, right, left | right:
+			return '┄'
+		}
+	}
+	return unicodeRunes[cl.lines]
+}
+
+func (c *canvas) String() string {
+	var b strings.Builder
+	for _, row := range c.cells {
+		var l strings.Builder
+		for _, cl := range row {
+			l.WriteRune(c.rune(cl))
+		}
+		b.WriteString(strings.TrimRight(l.String(), " "))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,513 @@
+// Package ascii draws graphs as box-and-arrow text diagrams for terminals
+// and plain-text transcripts.
+package ascii
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/tribal/tribal-cli/internal/client"
+	"github.com/tribal/tribal-cli/internal/graph"
+	"github.com/tribal/tribal-cli/internal/layout"
+)
+
+// DefaultMaxLabel is the length node and edge labels are truncated to.
+const DefaultMaxLabel = 30
+
+const (
+	boxHeight  = 3
+	slotGap    = 3
+	labelSpace = 1
+)
+
+// ErrTooWide is returned by Draw when the diagram does not fit the width.
+var ErrTooWide = errors.New("graph is too wide to draw")
+
+// Options control how a graph is drawn.
+type Options struct {
+	// Width is the maximum width in columns. Zero means unlimited.
+	Width int
+	// ASCII restricts output to plain ASCII instead of Unicode box drawing.
+	ASCII bool
+	// MaxLabel truncates labels; zero means DefaultMaxLabel.
+	MaxLabel int
+	// Highlight is the ID of a node drawn with a double border.
+	Highlight string
+}
+
+// slot is a node box or a dummy column in one layer of the drawing.
+type slot struct {
+	node  int // index into g.Nodes, or -1 for dummies
+	edge  int // for dummies, index into g.Edges
+	label string
+	row   int
+	x     int
+	width int
+	up    []*segment // segments arriving from the layer above
+	down  []*segment // segments leaving to the layer below
+}
+
+func (s *slot) center() int {
+	return s.x + s.width/2
+}
+
+// segment is the part of an edge between two adjacent layers.
+type segment struct {
+	edge    int
+	top     *slot
+	bottom  *slot
+	topX    int
+	bottomX int
+	track   int // horizontal track within the channel, or -1 when straight
+}
+
+// Draw renders g top to bottom using the layered layout: nodes are boxes,
+// edges run down between layers and directed edges end in an arrowhead.
+// Undirected edges are drawn with dashed lines.
+// Edge labels are written next to their edge where there is room and listed
+// below the diagram otherwise, together with self-loops. Draw returns
+// ErrTooWide if the diagram does not fit in opts.Width.
+func Draw(g *graph.Graph, opts Options) (string, error) {
+	if opts.MaxLabel <= 0 {
+		opts.MaxLabel = DefaultMaxLabel
+	}
+	layers := layout.Layers(g)
+	if len(layers) == 0 {
+		return "", nil
+	}
+
+	rows, nodeSlots := buildSlots(g, layers, opts)
+	segments := buildSegments(g, layers, rows, nodeSlots)
+
+	width := placeSlots(rows)
+	if opts.Width > 0 && width > opts.Width {
+		return "", ErrTooWide
+	}
+	channels := routeChannels(rows, segments)
+
+	// Vertical positions of every layer and of the channel below it.
+	rowY := make([]int, len(rows))
+	channelHeight := make([]int, len(rows))
+	height := 0
+	for l := range rows {
+		rowY[l] = height
+		height += boxHeight
+		if l < len(rows)-1 {
+			channelHeight[l] = 1
+			if channels[l] > 0 || hasSegments(rows[l]) {
+				channelHeight[l] = channels[l] + 2
+			}
+			height += channelHeight[l]
+		}
+	}
+
+	canvasWidth := width
+	if opts.Width > 0 {
+		canvasWidth = opts.Width
+	} else {
+		canvasWidth += opts.MaxLabel + 2
+	}
+	c := newCanvas(canvasWidth, height, opts.ASCII)
+
+	for l, row := range rows {
+		for _, s := range row {
+			y := rowY[l]
+			if s.node < 0 {
+				c.dashing = !g.Edges[s.edge].Directed
+				c.line(s.x, y, s.x, y+boxHeight-1)
+				c.dashing = false
+				continue
+			}
+			c.box(s.x, y, s.width, boxHeight, g.Nodes[s.node].ID == opts.Highlight)
+			c.text(s.x+(s.width-len([]rune(s.label)))/2, y+1, s.label)
+		}
+	}
+
+	for _, seg := range segments {
+		e := g.Edges[seg.edge]
+		l := seg.top.row
+		top := rowY[l] + boxHeight - 1
+		bottom := rowY[l+1]
+
+		// Arrowheads replace the connection to the box they point at.
+		arrowUp := e.Directed && seg.top.node >= 0 && g.Nodes[seg.top.node].ID == e.Target
+		arrowDown := e.Directed && seg.bottom.node >= 0 && g.Nodes[seg.bottom.node].ID == e.Target
+		start, end := top, bottom
+		if arrowUp {
+			start = top + 1
+		}
+		if arrowDown {
+			end = bottom - 1
+		}
+
+		c.dashing = !e.Directed
+		if seg.track < 0 {
+			c.path([2]int{seg.topX, start}, [2]int{seg.topX, end})
+		} else {
+			trackY := top + 2 + seg.track
+			c.path([2]int{seg.topX, start}, [2]int{seg.topX, trackY}, [2]int{seg.bottomX, trackY}, [2]int{seg.bottomX, end})
+		}
+		c.dashing = false
+
+		if arrowUp {
+			c.at(seg.topX, start).text = arrow(opts.ASCII, true)
+		}
+		if arrowDown {
+			c.at(seg.bottomX, end).text = arrow(opts.ASCII, false)
+		}
+	}
+
+	var legend []string
+	for i, e := range g.Edges {
+		label := edgeLabel(e, opts)
+		if e.Source == e.Target {
+			if g.Node(e.Source) != nil {
+				legend = append(legend, edgeLine(g, e, opts))
+			}
+			continue
+		}
+		if label == "" {
+			continue
+		}
+		if !placeLabel(c, segments, i, label, rowY) {
+			legend = append(legend, edgeLine(g, e, opts))
+		}
+	}
+
+	out := c.String()
+	if len(legend) > 0 {
+		out += "\n" + strings.Join(legend, "\n") + "\n"
+	}
+	return out, nil
+}
+
+func buildSlots(g *graph.Graph, layers [][]layout.Slot, opts Options) ([][]*slot, map[int]*slot) {
+	rows := make([][]*slot, len(layers))
+	nodeSlots := make(map[int]*slot)
+	for l, layer := range layers {
+		for _, ls := range layer {
+			s := &slot{node: ls.Node, edge: ls.Edge, row: l, width: 1}
+			if ls.Node >= 0 {
+				s.label = truncate(g.Nodes[ls.Node].Label, opts.MaxLabel)
+				if s.label == "" {
+					s.label = g.Nodes[ls.Node].ID
+				}
+				nodeSlots[ls.Node] = s
+			}
+			rows[l] = append(rows[l], s)
+		}
+	}
+	return rows, nodeSlots
+}
+
+// buildSegments splits every edge into one segment per pair of adjacent
+// layers it crosses, running from the upper end to the lower end.
+func buildSegments(g *graph.Graph, layers [][]layout.Slot, rows [][]*slot, nodeSlots map[int]*slot) []*segment {
+	index := make(map[string]int)
+	for i, n := range g.Nodes {
+		index[n.ID] = i
+	}
+	dummies := make(map[int][]*slot)
+	for l, layer := range layers {
+		for i, ls := range layer {
+			if ls.Node < 0 {
+				dummies[ls.Edge] = append(dummies[ls.Edge], rows[l][i])
+			}
+		}
+	}
+
+	var segments []*segment
+	for i, e := range g.Edges {
+		s, okS := index[e.Source]
+		t, okT := index[e.Target]
+		if !okS || !okT || s == t {
+			continue
+		}
+		upper, lower := nodeSlots[s], nodeSlots[t]
+		if upper.row > lower.row {
+			upper, lower = lower, upper
+		}
+
+		chain := append([]*slot{upper}, dummies[i]...)
+		chain = append(chain, lower)
+		for j := 1; j < len(chain); j++ {
+			seg := &segment{edge: i, top: chain[j-1], bottom: chain[j], track: -1}
+			chain[j-1].down = append(chain[j-1].down, seg)
+			chain[j].up = append(chain[j].up, seg)
+			segments = append(segments, seg)
+		}
+	}
+
+	for _, row := range rows {
+		for _, s := range row {
+			if s.node >= 0 {
+				s.width = max(len([]rune(s.label))+4, len(s.up)+2, len(s.down)+2)
+			}
+		}
+	}
+	return segments
+}
+
+// placeSlots positions slots left to right with each layer centred, then
+// assigns every segment its port on the boxes it joins. It returns the width
+// of the widest layer.
+func placeSlots(rows [][]*slot) int {
+	rowWidths := make([]int, len(rows))
+	width := 0
+	for l, row := range rows {
+		for i, s := range row {
+			if i > 0 {
+				rowWidths[l] += slotGap
+			}
+			rowWidths[l] += s.width
+		}
+		width = max(width, rowWidths[l])
+	}
+
+	for l, row := range rows {
+		x := (width - rowWidths[l]) / 2
+		for _, s := range row {
+			s.x = x
+			x += s.width + slotGap
+		}
+	}
+
+	for _, row := range rows {
+		for _, s := range row {
+			sort.SliceStable(s.down, func(i, j int) bool { return s.down[i].bottom.center() < s.down[j].bottom.center() })
+			sort.SliceStable(s.up, func(i, j int) bool { return s.up[i].top.center() < s.up[j].top.center() })
+			for i, seg := range s.down {
+				seg.topX = port(s, i, len(s.down))
+			}
+			for i, seg := range s.up {
+				seg.bottomX = port(s, i, len(s.up))
+			}
+		}
+	}
+	return width
+}
+
+// port spreads n connection points evenly along the inside of a box.
+func port(s *slot, i, n int) int {
+	if s.node < 0 {
+		return s.x
+	}
+	inner := s.width - 2
+	return s.x + 1 + (2*i+1)*inner/(2*n)
+}
+
+// routeChannels assigns the bent segments between each pair of layers to
+// horizontal tracks so that no two share a stretch of track, and a segment
+// leaving a column is routed above any segment entering that column from
+// below. It returns the number of tracks used in each channel.
+func routeChannels(rows [][]*slot, segments []*segment) []int {
+	tracks := make([]int, len(rows))
+	byChannel := make([][]*segment, len(rows))
+	for _, seg := range segments {
+		if seg.topX != seg.bottomX {
+			byChannel[seg.top.row] = append(byChannel[seg.top.row], seg)
+		}
+	}
+
+	for l, segs := range byChannel {
+		sort.SliceStable(segs, func(i, j int) bool { return span(segs[i])[0] < span(segs[j])[0] })
+
+		// above[b] lists the segments that must be on an earlier track than b.
+		above := make(map[*segment][]*segment)
+		for _, a := range segs {
+			for _, b := range segs {
+				if a != b && a.topX == b.bottomX {
+					above[b] = append(above[b], a)
+				}
+			}
+		}
+
+		var used [][]*segment
+		assigned := make(map[*segment]bool)
+		for len(assigned) < len(segs) {
+			next := pickSegment(segs, assigned, above)
+			track := 0
+			for _, a := range above[next] {
+				if assigned[a] && a.track >= track {
+					track = a.track + 1
+				}
+			}
+			for ; track < len(used); track++ {
+				if !overlaps(next, used[track]) {
+					break
+				}
+			}
+			if track == len(used) {
+				used = append(used, nil)
+			}
+			used[track] = append(used[track], next)
+			next.track = track
+			assigned[next] = true
+		}
+		tracks[l] = len(used)
+	}
+	return tracks
+}
+
+// pickSegment returns the leftmost unassigned segment whose constraints are
+// met, or the leftmost unassigned segment when constraints form a cycle.
+func pickSegment(segs []*segment, assigned map[*segment]bool, above map[*segment][]*segment) *segment {
+	var fallback *segment
+	for _, s := range segs {
+		if assigned[s] {
+			continue
+		}
+		if fallback == nil {
+			fallback = s
+		}
+		ready := true
+		for _, a := range above[s] {
+			if !assigned[a] {
+				ready = false
+				break
+			}
+		}
+		if ready {
+			return s
+		}
+	}
+	return fallback
+}
+
+func span(s *segment) [2]int {
+	if s.topX < s.bottomX {
+		return [2]int{s.topX, s.bottomX}
+	}
+	return [2]int{s.bottomX, s.topX}
+}
+
+func overlaps(s *segment, track []*segment) bool {
+	a := span(s)
+	for _, other := range track {
+		b := span(other)
+		if a[0] <= b[1] && b[0] <= a[1] {
+			return true
+		}
+	}
+	return false
+}
+
+func hasSegments(row []*slot) bool {
+	for _, s := range row {
+		if len(s.down) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// placeLabel writes an edge label on one of its horizontal tracks if the
+// track is long enough, or beside one of its vertical runs otherwise.
+// It reports false when there is no room.
+func placeLabel(c *canvas, segments []*segment, edge int, label string, rowY []int) bool {
+	n := len([]rune(label))
+	var mine []*segment
+	for _, seg := range segments {
+		if seg.edge == edge {
+			mine = append(mine, seg)
+		}
+	}
+
+	for _, seg := range mine {
+		if seg.track < 0 {
+			continue
+		}
+		sp := span(seg)
+		y := rowY[seg.top.row] + boxHeight + 1 + seg.track
+		if sp[1]-sp[0]-1 < n+2 {
+			continue
+		}
+		x := sp[0] + (sp[1]-sp[0]+1-n)/2
+		clear := true
+		for i := x - 1; i <= x+n; i++ {
+			if cl := c.at(i, y); cl == nil || cl.text != 0 || cl.lines != left|right {
+				clear = false
+			}
+		}
+		if clear {
+			c.text(x, y, label)
+			return true
+		}
+	}
+
+	for _, seg := range mine {
+		top := rowY[seg.top.row] + boxHeight
+		bottom := rowY[seg.top.row+1] - 1
+		x := seg.bottomX
+		from := top
+		if seg.track >= 0 {
+			from = top + 2 + seg.track
+		}
+		for y := from; y <= bottom; y++ {
+			for _, lx := range []int{x + 1 + labelSpace, x - labelSpace - n} {
+				if c.fits(lx, y, label) {
+					c.text(lx, y, label)
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func arrow(ascii, up bool) rune {
+	switch {
+	case ascii && up:
+		return '^'
+	case ascii:
+		return 'v'
+	case up:
+		return '▲'
+	default:
+		return '▼'
+	}
+}
+
+// connector returns the symbol for an edge leaving a node.
+func connector(directed, ascii bool) string {
+	switch {
+	case directed && ascii:
+		return "->"
+	case directed:
+		return "→"
+	case ascii:
+		return "--"
+	default:
+		return "—"
+	}
+}
+
+func edgeLabel(e client.Edge, opts Options) string {
+	if e.Label == nil {
+		return ""
+	}
+	return truncate(strings.TrimSpace(*e.Label), opts.MaxLabel)
+}
+
+// edgeLine describes an edge on one line, for the legend below a diagram.
+func edgeLine(g *graph.Graph, e client.Edge, opts Options) string {
+	text := fmt.Sprintf("%s %s %s", g.NodeLabel(e.Source), connector(e.Directed, opts.ASCII), g.NodeLabel(e.Target))
+	if label := edgeLabel(e, opts); label != "" {
+		text += ": " + label
+	}
+	return text
+}
+
+func truncate(s string, n int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	if n <= 3 {
+		return string(runes[:n])
+	}
+	return string(runes[:n-3]) + "..."
+}
@@ -0,0 +1,58 @@
+package ascii
+
+import (
+	"strings"
+
+	"github.com/tribal/tribal-cli/internal/graph"
+)
+
+// List renders g as an adjacency list: every node followed by its edges,
+// indented. Lines longer than opts.Width are truncated.
+func List(g *graph.Graph, opts Options) string {
+	if opts.MaxLabel <= 0 {
+		opts.MaxLabel = DefaultMaxLabel
+	}
+
+	var lines []string
+	for _, n := range g.Nodes {
+		label := n.Label
+		if label == "" {
+			label = n.ID
+		}
+		if n.ID == opts.Highlight {
+			label = "* " + label
+		}
+		lines = append(lines, label)
+
+		for _, nb := range g.Neighbors(n.ID) {
+			arrow := connector(false, opts.ASCII)
+			switch nb.Direction {
+			case graph.Outgoing:
+				arrow = connector(true, opts.ASCII)
+			case graph.Incoming:
+				arrow = "←"
+				if opts.ASCII {
+					arrow = "<-"
+				}
+			}
+			line := "  " + arrow + " " + nb.Node.Label
+			if l := edgeLabel(nb.Edge, opts); l != "" {
+				line += "  (" + l + ")"
+			}
+			lines = append(lines, line)
+		}
+	}
+
+	for i, l := range lines {
+		lines[i] = clip(l, opts.Width)
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func clip(s string, width int) string {
+	runes := []rune(s)
+	if width <= 0 || len(runes) <= width {
+		return s
+	}
+	return string(runes[:width])
+}
@@ -0,0 +1,478 @@
+// Package briefing assembles the parts of the local graphs relevant to a
+// task into a compact, token-budgeted briefing for coding agents.
+package briefing
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/tribal/tribal-cli/internal/anchor"
+	"github.com/tribal/tribal-cli/internal/client"
+	"github.com/tribal/tribal-cli/internal/graph"
+	"github.com/tribal/tribal-cli/internal/search"
+)
+
+const (
+	FormatMarkdown = "markdown"
+	FormatJSON     = "json"
+)
+
+const (
+	// footerTokens is held back from the budget for the list of omitted
+	// nodes.
+	footerTokens = 100
+	// minMarkupTokens is the least markup worth keeping when a node's
+	// markup has to be truncated to fit.
+	minMarkupTokens = 20
+	// maxOmittedListed caps the omitted nodes named in the output.
+	maxOmittedListed = 8
+)
+
+type Options struct {
+	// Graph restricts the briefing to the graph with this title.
+	Graph string
+	// Seeds is the number of best-matching nodes to expand from.
+	Seeds int
+	// Depth is the number of hops around each seed to include.
+	Depth int
+	// MaxTokens is the approximate size limit of the rendered briefing.
+	MaxTokens int
+	// Format is FormatMarkdown or FormatJSON; the budget is measured
+	// against the chosen format.
+	Format string
+}
+
+type Briefing struct {
+	Task      string     `json:"task"`
+	MaxTokens int        `json:"max_tokens"`
+	Tokens    int        `json:"estimated_tokens"`
+	Graphs    []*Section `json:"graphs"`
+	// Omitted lists the first nodes left out; OmittedTotal counts them all.
+	Omitted      []Omitted `json:"omitted,omitempty"`
+	OmittedTotal int       `json:"omitted_total,omitempty"`
+}
+
+// Section holds the nodes taken from one graph, most relevant first, and
+// the relationships between them.
+type Section struct {
+	Title         string         `json:"title"`
+	Description   string         `json:"description,omitempty"`
+	Nodes         []Node         `json:"nodes"`
+	Relationships []Relationship `json:"relationships"`
+
+	path     string
+	included map[string]string // node ID to label
+}
+
+type Node struct {
+	ID     string   `json:"id"`
+	Label  string   `json:"label"`
+	Markup string   `json:"markup,omitempty"`
+	Code   []string `json:"code,omitempty"`
+	// Distance is the number of hops from the nearest matching node.
+	Distance  int  `json:"distance"`
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// Relationship is an edge between two included nodes, by label.
+type Relationship struct {
+	Source   string `json:"source"`
+	Target   string `json:"target"`
+	Label    string `json:"label,omitempty"`
+	Directed bool   `json:"directed"`
+}
+
+// Omitted is a relevant node left out to stay within the budget.
+type Omitted struct {
+	Graph string `json:"graph"`
+	Node  string `json:"node"`
+}
+
+type candidate struct {
+	file     graph.File
+	node     client.Node
+	distance int
+	score    float64
+	order    int
+}
+
+// EstimateTokens approximates the number of model tokens in s at four
+// characters per token.
+func EstimateTokens(s string) int {
+	return (utf8.RuneCountInString(s) + 3) / 4
+}
+
+// Build searches files for the task, expands the best-matching nodes to
+// their neighborhoods and keeps the most relevant nodes that fit the
+// budget. Nodes matching the task rank first, then their neighbors by
+// distance; markup is truncated rather than dropped where that makes a
+// node fit.
+func Build(files []graph.File, task string, opts Options) *Briefing {
+	b := &Briefing{Task: task, MaxTokens: opts.MaxTokens, Graphs: []*Section{}}
+
+	results := search.Files(files, task, search.Options{Graph: opts.Graph})
+	byPath := make(map[string]graph.File)
+	for _, f := range files {
+		byPath[f.Path] = f
+	}
+
+	candidates := expand(seeds(byPath, results, opts.Seeds), opts.Depth)
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, c := candidates[i], candidates[j]
+		if a.score != c.score {
+			return a.score > c.score
+		}
+		if a.distance != c.distance {
+			return a.distance < c.distance
+		}
+		return a.order < c.order
+	})
+
+	// Graphs whose title or description match are listed even when none
+	// of their nodes fit.
+	for _, r := range results {
+		if r.Kind == search.KindGraph {
+			if f, ok := byPath[r.GraphFile]; ok {
+				b.section(f)
+			}
+		}
+	}
+
+	budget := opts.MaxTokens - footerTokens
+	used := EstimateTokens(b.Render(opts.Format))
+	for _, c := range candidates {
+		_, existing := b.find(c.file.Path)
+		s := b.section(c.file)
+		extra := 0
+		if !existing {
+			extra = sectionCost(s, opts.Format)
+		}
+
+		n := Node{ID: c.node.ID, Label: c.node.Label, Markup: strings.TrimSpace(deref(c.node.Markup)), Distance: c.distance}
+		for _, a := range c.node.Anchors {
+			n.Code = append(n.Code, anchor.String(a))
+		}
+		rels := relationships(c.file.Graph, c.node.ID, s.included)
+		for _, r := range rels {
+			extra += relationshipCost(r, opts.Format)
+		}
+
+		cost := extra + nodeCost(n, opts.Format)
+		if used+cost > budget && n.Markup != "" {
+			short := n
+			short.Markup, short.Truncated = "", true
+			// Formatting around the markup (JSON escaping, the key itself)
+			// can push the first guess over; shrink until it fits.
+			for room := budget - used - extra - nodeCost(short, opts.Format); room >= minMarkupTokens; {
+				short.Markup = truncate(n.Markup, room)
+				over := used + extra + nodeCost(short, opts.Format) - budget
+				if over <= 0 {
+					n, cost = short, extra+nodeCost(short, opts.Format)
+					break
+				}
+				room -= over
+			}
+		}
+		if used+cost > budget {
+			if !existing {
+				b.drop(s)
+			}
+			if len(b.Omitted) < maxOmittedListed {
+				b.Omitted = append(b.Omitted, Omitted{Graph: c.file.Graph.Title, Node: c.node.Label})
+			}
+			b.OmittedTotal++
+			continue
+		}
+
+		s.Nodes = append(s.Nodes, n)
+		s.Relationships = append(s.Relationships, rels...)
+		s.included[n.ID] = n.Label
+		used += cost
+	}
+
+	// Sections appear in the order their first node was chosen; graphs
+	// that only matched by title come last.
+	sort.SliceStable(b.Graphs, func(i, j int) bool {
+		return len(b.Graphs[i].Nodes) > 0 && len(b.Graphs[j].Nodes) == 0
+	})
+	b.Tokens = EstimateTokens(b.Render(opts.Format))
+	return b
+}
+
+// seed is a node, or the endpoints of an edge, matching the task.
+type seed struct {
+	file  graph.File
+	nodes []string
+	score float64
+}
+
+// seeds returns the best-matching nodes with their search scores. An edge
+// match seeds both of its endpoints.
+func seeds(files map[string]graph.File, results []search.Result, limit int) []seed {
+	var out []seed
+	seen := make(map[string]bool)
+	for _, r := range results {
+		if len(out) >= limit {
+			break
+		}
+		f, ok := files[r.GraphFile]
+		key := r.GraphFile + "\x00" + string(r.Kind) + "\x00" + r.ID
+		if !ok || seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		switch r.Kind {
+		case search.KindNode:
+			out = append(out, seed{file: f, nodes: []string{r.ID}, score: float64(r.Score)})
+		case search.KindEdge:
+			if e := f.Graph.Edge(r.ID); e != nil {
+				out = append(out, seed{file: f, nodes: []string{e.Source, e.Target}, score: float64(r.Score)})
+			}
+		}
+	}
+	return out
+}
+
+// expand walks depth hops out from every seed, scoring each reachable node
+// by the best seed score divided by one more than its distance.
+func expand(seeds []seed, depth int) []candidate {
+	found := make(map[string]*candidate)
+	var order []string
+
+	visit := func(f graph.File, id string, distance int, score float64) {
+		n := f.Graph.Node(id)
+		if n == nil {
+			return
+		}
+		key := f.Path + "\x00" + id
+		c, ok := found[key]
+		if !ok {
+			c = &candidate{file: f, node: *n, distance: distance, order: len(order)}
+			found[key] = c
+			order = append(order, key)
+		}
+		if distance < c.distance {
+			c.distance = distance
+		}
+		if s := score / float64(distance+1); s > c.score {
+			c.score = s
+		}
+	}
+
+	for _, sd := range seeds {
+		for _, start := range sd.nodes {
+			distance := map[string]int{start: 0}
+			queue := []string{start}
+			for len(queue) > 0 {
+				cur := queue[0]
+				queue = queue[1:]
+				visit(sd.file, cur, distance[cur], sd.score)
+				if distance[cur] == depth {
+					continue
+				}
+				for _, nb := range sd.file.Graph.Neighbors(cur) {
+					if _, seen := distance[nb.Node.ID]; !seen {
+						distance[nb.Node.ID] = distance[cur] + 1
+						queue = append(queue, nb.Node.ID)
+					}
+				}
+			}
+		}
+	}
+
+	out := make([]candidate, 0, len(order))
+	for _, key := range order {
+		out = append(out, *found[key])
+	}
+	return out
+}
+
+// relationships returns the edges joining id to nodes already included,
+// including self-loops.
+func relationships(g *graph.Graph, id string, included map[string]string) []Relationship {
+	label := g.NodeLabel(id)
+	var rels []Relationship
+	for _, e := range g.Edges {
+		var source, target string
+		switch {
+		case e.Source == id && e.Target == id:
+			source, target = label, label
+		case e.Source == id && included[e.Target] != "":
+			source, target = label, included[e.Target]
+		case e.Target == id && included[e.Source] != "":
+			source, target = included[e.Source], label
+		default:
+			continue
+		}
+		rels = append(rels, Relationship{Source: source, Target: target, Label: deref(e.Label), Directed: e.Directed})
+	}
+	return rels
+}
+
+func (b *Briefing) find(path string) (*Section, bool) {
+	for _, s := range b.Graphs {
+		if s.path == path {
+			return s, true
+		}
+	}
+	return nil, false
+}
+
+func (b *Briefing) section(f graph.File) *Section {
+	if s, ok := b.find(f.Path); ok {
+		return s
+	}
+	s := &Section{
+		Title:         f.Graph.Title,
+		Description:   strings.TrimSpace(f.Graph.Description()),
+		Nodes:         []Node{},
+		Relationships: []Relationship{},
+		path:          f.Path,
+		included:      make(map[string]string),
+	}
+	b.Graphs = append(b.Graphs, s)
+	return s
+}
+
+func (b *Briefing) drop(s *Section) {
+	for i := range b.Graphs {
+		if b.Graphs[i] == s {
+			b.Graphs = append(b.Graphs[:i], b.Graphs[i+1:]...)
+			return
+		}
+	}
+}
+
+// Render returns the briefing in the given format.
+func (b *Briefing) Render(format string) string {
+	if format == FormatJSON {
+		return string(marshal(b))
+	}
+
+	var w strings.Builder
+	fmt.Fprintf(&w, "# Context: %s\n", b.Task)
+	if len(b.Graphs) == 0 {
+		w.WriteString("\nNo graphs, nodes or edges match this task.\n")
+	}
+	for _, s := range b.Graphs {
+		w.WriteString(sectionMarkdown(s))
+		for _, n := range s.Nodes {
+			w.WriteString(nodeMarkdown(n))
+		}
+		if len(s.Relationships) > 0 {
+			w.WriteString("\n**Relationships**\n\n")
+			for _, r := range s.Relationships {
+				w.WriteString(relationshipMarkdown(r))
+			}
+		}
+	}
+
+	if len(b.Omitted) > 0 {
+		var names []string
+		for _, o := range b.Omitted {
+			names = append(names, o.Node+" ("+o.Graph+")")
+		}
+		if more := b.OmittedTotal - len(b.Omitted); more > 0 {
+			names = append(names, fmt.Sprintf("and %d more", more))
+		}
+		fmt.Fprintf(&w, "\n_Left out to fit the token budget: %s._\n", strings.Join(names, ", "))
+	}
+	return w.String()
+}
+
+func sectionMarkdown(s *Section) string {
+	text := "\n## " + s.Title + "\n"
+	if s.Description != "" {
+		text += "\n" + s.Description + "\n"
+	}
+	return text
+}
+
+func nodeMarkdown(n Node) string {
+	text := "\n### " + n.Label + "\n"
+	switch {
+	case n.Distance == 1:
+		text += "\n_Neighbor: 1 hop from a matching node._\n"
+	case n.Distance > 1:
+		text += fmt.Sprintf("\n_Neighbor: %d hops from a matching node._\n", n.Distance)
+	}
+	if n.Markup != "" {
+		text += "\n" + n.Markup + "\n"
+	}
+	if n.Truncated {
+		text += "\n_(truncated)_\n"
+	}
+	if len(n.Code) > 0 {
+		text += "\nCode: `" + strings.Join(n.Code, "`, `") + "`\n"
+	}
+	return text
+}
+
+func relationshipMarkdown(r Relationship) string {
+	arrow := " — "
+	if r.Directed {
+		arrow = " → "
+	}
+	text := "- **" + r.Source + "**" + arrow + "**" + r.Target + "**"
+	if r.Label != "" {
+		text += ": " + r.Label
+	}
+	return text + "\n"
+}
+
+func sectionCost(s *Section, format string) int {
+	if format == FormatJSON {
+		return EstimateTokens(string(marshal(Section{Title: s.Title, Description: s.Description})))
+	}
+	return EstimateTokens(sectionMarkdown(s))
+}
+
+func nodeCost(n Node, format string) int {
+	if format == FormatJSON {
+		return EstimateTokens(string(marshal(n)))
+	}
+	return EstimateTokens(nodeMarkdown(n))
+}
+
+func relationshipCost(r Relationship, format string) int {
+	if format == FormatJSON {
+		return EstimateTokens(string(marshal(r)))
+	}
+	return EstimateTokens(relationshipMarkdown(r))
+}
+
+// marshal encodes v compactly; whitespace would only spend the budget.
+func marshal(v interface{}) []byte {
+	data, _ := json.Marshal(v)
+	return data
+}
+
+// truncate shortens s to about the given number of tokens, breaking at a
+// word boundary.
+func truncate(s string, tokens int) string {
+	limit := tokens * 4
+	if utf8.RuneCountInString(s) <= limit {
+		return s
+	}
+	runes := []rune(s)[:limit-1]
+	cut := len(runes)
+	for i := len(runes) - 1; i > len(runes)/2; i-- {
+		if unicode.IsSpace(runes[i]) {
+			cut = i
+			break
+		}
+	}
+	return strings.TrimRightFunc(string(runes[:cut]), unicode.IsSpace) + "…"
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
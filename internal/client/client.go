@@ -55,6 +55,20 @@ type Node struct {
 	Markup   *string    `json:"markup,omitempty"`
 	Position Position   `json:"position"`
 	Size     *Size      `json:"size,omitempty"`
+	Anchors  []Anchor   `json:"anchors,omitempty"`
+}
+
+// Anchor links a node to a location in the source tree. Paths are relative
+// to the repository root and use forward slashes. Hash fingerprints the
+// anchored content and Commit is the git HEAD when the anchor was recorded.
+type Anchor struct {
+	Path      string `json:"path"`
+	StartLine int    `json:"start_line,omitempty"`
+	EndLine   int    `json:"end_line,omitempty"`
+	Symbol    string `json:"symbol,omitempty"`
+	Package   bool   `json:"package,omitempty"`
+	Hash      string `json:"hash,omitempty"`
+	Commit    string `json:"commit,omitempty"`
 }
 
 type Edge struct {
@@ -96,6 +110,24 @@ type UpdateGraphRequest struct {
 	Message     string                 `json:"message"`
 }
 
+// PersonalAccessToken is an API token for scripts and CI. Token is only
+// returned when the token is created.
+type PersonalAccessToken struct {
+	ID         uuid.UUID  `json:"id"`
+	Name       string     `json:"name"`
+	Token      string     `json:"token,omitempty"`
+	Scopes     []string   `json:"scopes,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+}
+
+type CreateTokenRequest struct {
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
 type SearchRequest struct {
 	Query     string     `json:"query"`
 	UserID    *uuid.UUID `json:"user_id,omitempty"`
@@ -234,6 +266,46 @@ func (c *Client) GetMe() (*User, error) {
 	return &user, nil
 }
 
+// Personal access token methods
+func (c *Client) CreateToken(req CreateTokenRequest) (*PersonalAccessToken, error) {
+	resp, err := c.doRequest("POST", "/api/v1/auth/tokens", req)
+	if err != nil {
+		return nil, err
+	}
+
+	var token PersonalAccessToken
+	if err := c.handleResponse(resp, &token); err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+func (c *Client) ListTokens() ([]PersonalAccessToken, error) {
+	resp, err := c.doRequest("GET", "/api/v1/auth/tokens", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Tokens []PersonalAccessToken `json:"tokens"`
+	}
+	if err := c.handleResponse(resp, &result); err != nil {
+		return nil, err
+	}
+
+	return result.Tokens, nil
+}
+
+func (c *Client) RevokeToken(id string) error {
+	resp, err := c.doRequest("DELETE", "/api/v1/auth/tokens/"+id, nil)
+	if err != nil {
+		return err
+	}
+
+	return c.handleResponse(resp, nil)
+}
+
 // Graph methods
 func (c *Client) CreateGraph(req CreateGraphRequest) (*Graph, error) {
 	resp, err := c.doRequest("POST", "/api/v1/graphs", req)
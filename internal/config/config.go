@@ -10,7 +10,11 @@ import (
 
 type Config struct {
 	Version     string `json:"version"`
+	// Default remote for push and fetch
 	Remote      string `json:"remote"`
+	// Named registries, and where each graph is published, by graph title
+	Remotes  map[string]*Remote   `json:"remotes,omitempty"`
+	Tracking map[string]*Tracking `json:"tracking,omitempty"`
 	Graphs      map[string]interface{} `json:"graphs"`
 	CurrentGraph string `json:"current_graph,omitempty"`
 	CurrentGraphFile string `json:"current_graph_file,omitempty"`
@@ -18,11 +22,27 @@ type Config struct {
 	LatestCommitFile string `json:"latest_commit_file,omitempty"`
 	LastPushedCommit string `json:"last_pushed_commit,omitempty"`
 	LastPushedFile string `json:"last_pushed_file,omitempty"`
-	// Network configuration
+	StagedGraph string `json:"staged_graph,omitempty"`
+	StagedGraphFile string `json:"staged_graph_file,omitempty"`
+	// Directory regenerated with a markdown export of every graph after each commit
+	MarkdownExportDir string `json:"markdown_export_dir,omitempty"`
+	// Commit changed graphs from the git post-commit hook, installed with 'tribal init --git-hooks'
+	GitAutoCommit bool `json:"git_auto_commit,omitempty"`
+	// Template TRIBAL.md is rendered from, by name or path, set with 'tribal init --template'
+	DocsTemplate string `json:"docs_template,omitempty"`
+	// Where registry tokens are kept, file or keyring
+	CredentialStore string `json:"credential_store,omitempty"`
+	// Network configuration. Credentials are filled in from the credential
+	// store and never written to the repository config
 	RegistryURL string `json:"registry_url,omitempty"`
 	Token       string `json:"token,omitempty"`
 	Username    string `json:"username,omitempty"`
 	UserID      string `json:"user_id,omitempty"`
+
+	// Values of global settings before inherited ones were filled in, and
+	// the inherited values, so Save only writes the repository's own
+	local     map[string]string
+	inherited map[string]string
 }
 
 const (
@@ -53,20 +73,35 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("failed to parse config: %w", err)
 	}
 
-	// Set default registry URL if not set
-	if config.RegistryURL == "" {
-		config.RegistryURL = DefaultRegistryURL
+	// Fill in settings from the environment, user and system config
+	if err := config.applyLayers(true); err != nil {
+		return nil, err
 	}
 
 	return &config, nil
 }
 
+// Encode returns the config as it is written to disk.
+func (c *Config) Encode() ([]byte, error) {
+	data, err := json.MarshalIndent(c.own(), "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize config: %w", err)
+	}
+	return data, nil
+}
+
 func (c *Config) Save() error {
 	configPath := GetConfigPath()
+
+	if c.credentialsChanged() {
+		if err := c.SaveCredentials(); err != nil {
+			return err
+		}
+	}
 	
-	data, err := json.MarshalIndent(c, "", "  ")
+	data, err := c.Encode()
 	if err != nil {
-		return fmt.Errorf("failed to serialize config: %w", err)
+		return err
 	}
 
 	if err := ioutil.WriteFile(configPath, data, 0644); err != nil {
@@ -76,8 +111,10 @@ func (c *Config) Save() error {
 	return nil
 }
 
+// IsAuthenticated reports whether there is a token for the registry. A
+// token from TRIBAL_TOKEN comes without a username.
 func (c *Config) IsAuthenticated() bool {
-	return c.Token != "" && c.Username != ""
+	return c.Token != ""
 }
 
 func (c *Config) SetAuth(token, username, userID string) {
@@ -97,10 +134,14 @@ func (c *Config) SetRegistryURL(url string) {
 }
 
 func CreateDefaultConfig() *Config {
-	return &Config{
+	config := &Config{
 		Version:     "1.0.0",
 		Remote:      "",
 		Graphs:      make(map[string]interface{}),
-		RegistryURL: DefaultRegistryURL,
 	}
+	// Unreadable user or system config leaves the built-in defaults
+	if err := config.applyLayers(false); err != nil {
+		config.RegistryURL = DefaultRegistryURL
+	}
+	return config
 }
\ No newline at end of file
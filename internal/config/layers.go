@@ -0,0 +1,438 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/tribal/tribal-cli/internal/credentials"
+)
+
+// Settings are resolved from several layers. From highest to lowest
+// precedence:
+//
+//  1. environment variables (TRIBAL_REGISTRY_URL, TRIBAL_TOKEN)
+//  2. the repository config, .tribal/config.json
+//  3. the user config, $XDG_CONFIG_HOME/tribal/config.json
+//  4. the system config, /etc/tribal/config.json
+//  5. built-in defaults
+//
+// Only global settings are read from the user and system files; the rest
+// of the repository config is state such as the checked-out graph.
+//
+// Credentials are never kept in config files. They come from TRIBAL_TOKEN
+// or the per-user credential store, looked up by the effective registry
+// URL; tokens found in older config files are moved there when read.
+
+// Source identifies the layer a setting was read from.
+type Source string
+
+const (
+	SourceDefault Source = "default"
+	SourceSystem  Source = "system"
+	SourceUser    Source = "user"
+	SourceLocal   Source = "local"
+	SourceEnv     Source = "env"
+	// SourceCredentials is the per-user credential store.
+	SourceCredentials Source = "credentials"
+)
+
+// Setting is a configuration key that can be read and written with
+// 'tribal config'.
+type Setting struct {
+	Key string
+	// Env names the environment variable overriding the setting, if any.
+	Env string
+	// Global settings may also be set in the user and system config.
+	Global bool
+	// Bool settings hold true or false.
+	Bool bool
+	// Secret values are masked when listed.
+	Secret bool
+	// Credential settings live in the credential store and are written by
+	// 'tribal login', not 'tribal config'.
+	Credential bool
+	// Choices restricts the values a setting accepts.
+	Choices []string
+	Help    string
+
+	field func(c *Config) *string
+}
+
+// Settings lists the keys 'tribal config' understands.
+var Settings = []Setting{
+	{Key: "registry_url", Env: "TRIBAL_REGISTRY_URL", Global: true, Help: "Registry URL",
+		field: func(c *Config) *string { return &c.RegistryURL }},
+	{Key: "token", Env: "TRIBAL_TOKEN", Secret: true, Credential: true, Help: "Registry access token",
+		field: func(c *Config) *string { return &c.Token }},
+	{Key: "username", Credential: true, Help: "Registry username",
+		field: func(c *Config) *string { return &c.Username }},
+	{Key: "user_id", Credential: true, Help: "Registry user ID",
+		field: func(c *Config) *string { return &c.UserID }},
+	{Key: "credential_store", Global: true, Choices: []string{credentials.BackendFile, credentials.BackendKeyring},
+		Help:  "Where registry tokens are kept: file, or the OS keyring",
+		field: func(c *Config) *string { return &c.CredentialStore }},
+	{Key: "docs_template", Global: true, Help: "Template TRIBAL.md is rendered from",
+		field: func(c *Config) *string { return &c.DocsTemplate }},
+	{Key: "markdown_export_dir", Help: "Directory regenerated with a markdown export after each commit"},
+	{Key: "git_auto_commit", Bool: true, Help: "Commit changed graphs from the git post-commit hook"},
+}
+
+// Lookup returns the setting with the given key.
+func Lookup(key string) (Setting, bool) {
+	for _, s := range Settings {
+		if s.Key == key {
+			return s, true
+		}
+	}
+	return Setting{}, false
+}
+
+// Parse converts a value given on the command line to what is stored.
+func (s Setting) Parse(value string) (interface{}, error) {
+	if len(s.Choices) > 0 {
+		for _, choice := range s.Choices {
+			if value == choice {
+				return value, nil
+			}
+		}
+		return nil, fmt.Errorf("%s must be one of: %s", s.Key, strings.Join(s.Choices, ", "))
+	}
+	if !s.Bool {
+		return value, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be true or false", s.Key)
+	}
+	return b, nil
+}
+
+// SystemConfigPath is the machine-wide config file.
+func SystemConfigPath() string {
+	if runtime.GOOS == "windows" {
+		return filepath.Join(os.Getenv("ProgramData"), "tribal", "config.json")
+	}
+	return filepath.Join("/etc", "tribal", "config.json")
+}
+
+// UserConfigPath is the per-user config file, under $XDG_CONFIG_HOME (or
+// the platform's equivalent).
+func UserConfigPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot locate user config directory: %w", err)
+	}
+	return filepath.Join(dir, "tribal", ConfigFile), nil
+}
+
+// ReadFile reads a config file as raw key/value pairs. A missing file is
+// empty.
+func ReadFile(path string) (map[string]interface{}, error) {
+	values := make(map[string]interface{})
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return values, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return values, nil
+}
+
+// WriteFile writes raw key/value pairs to a config file, creating its
+// directory.
+func WriteFile(path string, values map[string]interface{}, perm os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	data, err := json.MarshalIndent(values, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize config: %w", err)
+	}
+	if err := ioutil.WriteFile(path, data, perm); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// Value is a resolved setting and where it came from.
+type Value struct {
+	Setting Setting
+	Value   string
+	Source  Source
+}
+
+// Resolve returns the effective value of every setting that has one,
+// sorted by key. The repository layer is skipped outside a repository.
+func Resolve() ([]Value, error) {
+	layers, err := readLayers(true)
+	if err != nil {
+		return nil, err
+	}
+
+	var values []Value
+	for _, s := range Settings {
+		if v, source, ok := layers.resolve(s); ok {
+			values = append(values, Value{Setting: s, Value: v, Source: source})
+		}
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i].Setting.Key < values[j].Setting.Key })
+	return values, nil
+}
+
+type layers struct {
+	system, user, local map[string]interface{}
+	// credential is the stored login for the effective registry.
+	credential credentials.Credential
+}
+
+// readLayers reads the config files, including the repository's when
+// withLocal is set, and the credentials for the resolved registry.
+func readLayers(withLocal bool) (*layers, error) {
+	var l layers
+	var err error
+	if l.system, err = ReadFile(SystemConfigPath()); err != nil {
+		return nil, err
+	}
+	l.user = map[string]interface{}{}
+	userPath, pathErr := UserConfigPath()
+	if pathErr == nil {
+		if l.user, err = ReadFile(userPath); err != nil {
+			return nil, err
+		}
+	}
+	l.local = map[string]interface{}{}
+	if withLocal {
+		if l.local, err = ReadFile(GetConfigPath()); err != nil {
+			return nil, err
+		}
+	}
+
+	store, err := l.store()
+	if err != nil {
+		return nil, err
+	}
+	if pathErr == nil {
+		if err := l.migrate(store, userPath, l.user, 0600); err != nil {
+			return nil, err
+		}
+	}
+	if withLocal {
+		if err := l.migrate(store, GetConfigPath(), l.local, 0644); err != nil {
+			return nil, err
+		}
+	}
+
+	registryURL, _ := Lookup("registry_url")
+	registry, _, _ := l.resolve(registryURL)
+	if l.credential, _, err = store.Get(registry); err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+// store opens the credential store selected by credential_store.
+func (l *layers) store() (*credentials.Store, error) {
+	s, _ := Lookup("credential_store")
+	backend, _, _ := l.resolve(s)
+	return credentials.Open(backend)
+}
+
+// migrate moves credentials written into a config file by earlier versions
+// to the credential store, keyed by the registry the file pointed at, and
+// rewrites the file without them. A login already in the store is newer
+// and is kept.
+func (l *layers) migrate(store *credentials.Store, path string, values map[string]interface{}, perm os.FileMode) error {
+	var c credentials.Credential
+	c.Token, _ = stringValue(values["token"])
+	c.Username, _ = stringValue(values["username"])
+	c.UserID, _ = stringValue(values["user_id"])
+	if c.Empty() {
+		return nil
+	}
+
+	registry, ok := stringValue(values["registry_url"])
+	if !ok {
+		registry, ok = stringValue(l.user["registry_url"])
+	}
+	if !ok {
+		registry, ok = stringValue(l.system["registry_url"])
+	}
+	if !ok {
+		registry = DefaultRegistryURL
+	}
+
+	if _, found, err := store.Get(registry); err != nil {
+		return err
+	} else if !found && c.Token != "" {
+		if err := store.Set(registry, c); err != nil {
+			return fmt.Errorf("failed to move credentials out of %s: %w", path, err)
+		}
+	}
+	delete(values, "token")
+	delete(values, "username")
+	delete(values, "user_id")
+	if err := WriteFile(path, values, perm); err != nil {
+		return fmt.Errorf("failed to move credentials out of %s: %w", path, err)
+	}
+	credPath, _ := credentials.Path()
+	fmt.Fprintf(os.Stderr, "Moved registry credentials from %s to %s\n", path, credPath)
+	return nil
+}
+
+// resolve finds the highest-precedence value of a setting.
+func (l *layers) resolve(s Setting) (string, Source, bool) {
+	if s.Env != "" {
+		if v := os.Getenv(s.Env); v != "" {
+			return v, SourceEnv, true
+		}
+	}
+	if s.Credential {
+		var v string
+		switch s.Key {
+		case "token":
+			v = l.credential.Token
+		case "username":
+			v = l.credential.Username
+		case "user_id":
+			v = l.credential.UserID
+		}
+		return v, SourceCredentials, v != ""
+	}
+	if v, ok := stringValue(l.local[s.Key]); ok && !(s.Key == "registry_url" && v == DefaultRegistryURL) {
+		return v, SourceLocal, true
+	}
+	if s.Global {
+		if v, ok := stringValue(l.user[s.Key]); ok {
+			return v, SourceUser, true
+		}
+		if v, ok := stringValue(l.system[s.Key]); ok {
+			return v, SourceSystem, true
+		}
+	}
+	if s.Key == "registry_url" {
+		return DefaultRegistryURL, SourceDefault, true
+	}
+	return "", "", false
+}
+
+func stringValue(v interface{}) (string, bool) {
+	switch v := v.(type) {
+	case string:
+		return v, v != ""
+	case bool:
+		return strconv.FormatBool(v), true
+	case nil:
+		return "", false
+	default:
+		return fmt.Sprint(v), true
+	}
+}
+
+// applyLayers fills global settings the repository config leaves unset
+// from the environment, user and system config, remembering what was
+// inherited so Save writes back only the repository's own values.
+//
+// Earlier versions wrote the default registry URL into every repository
+// config; it is treated as unset so user and system settings apply.
+func (c *Config) applyLayers(withLocal bool) error {
+	l, err := readLayers(withLocal)
+	if err != nil {
+		return err
+	}
+
+	c.local = make(map[string]string)
+	c.inherited = make(map[string]string)
+	for _, s := range Settings {
+		if s.field == nil {
+			continue
+		}
+		field := s.field(c)
+		local := *field
+		if s.Credential || (s.Key == "registry_url" && local == DefaultRegistryURL) {
+			local = ""
+		}
+		v, source, ok := l.resolve(s)
+		if s.Credential && !ok {
+			*field = ""
+			continue
+		}
+		if !ok || source == SourceLocal {
+			continue
+		}
+		c.local[s.Key] = local
+		c.inherited[s.Key] = v
+		*field = v
+	}
+	return nil
+}
+
+// own returns a copy of the config holding only the repository's values,
+// undoing applyLayers for settings that were not changed since. Credentials
+// are always left out.
+func (c *Config) own() *Config {
+	out := *c
+	for _, s := range Settings {
+		if s.field == nil {
+			continue
+		}
+		if s.Credential {
+			*s.field(&out) = ""
+			continue
+		}
+		v, ok := c.inherited[s.Key]
+		if field := s.field(&out); ok && *field == v {
+			*field = c.local[s.Key]
+		}
+	}
+	return &out
+}
+
+// credentialsChanged reports whether the login differs from what was read
+// from the environment and credential store.
+func (c *Config) credentialsChanged() bool {
+	for _, s := range Settings {
+		if s.Credential && *s.field(c) != c.inherited[s.Key] {
+			return true
+		}
+	}
+	return false
+}
+
+// SaveCredentials writes the login to the credential store under the
+// config's registry URL, or removes it there when cleared. Unlike Save it
+// works outside a repository.
+func (c *Config) SaveCredentials() error {
+	store, err := credentials.Open(c.CredentialStore)
+	if err != nil {
+		return err
+	}
+	cred := credentials.Credential{Token: c.Token, Username: c.Username, UserID: c.UserID}
+	if cred.Empty() {
+		err = store.Delete(c.RegistryURL)
+	} else {
+		err = store.Set(c.RegistryURL, cred)
+	}
+	if err != nil {
+		return err
+	}
+	if c.inherited == nil {
+		c.inherited = make(map[string]string)
+	}
+	for _, s := range Settings {
+		if s.Credential {
+			c.inherited[s.Key] = *s.field(c)
+		}
+	}
+	return nil
+}
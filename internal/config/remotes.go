@@ -0,0 +1,178 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/tribal/tribal-cli/internal/credentials"
+)
+
+// Remote is a named registry graphs are pushed to and fetched from.
+type Remote struct {
+	URL string `json:"url"`
+}
+
+// Tracking records where a graph is published: its ID on each remote it
+// was pushed to or fetched from, and the remote push and fetch use for it
+// when none is named.
+type Tracking struct {
+	Upstream string            `json:"upstream,omitempty"`
+	IDs      map[string]string `json:"ids,omitempty"`
+}
+
+var remoteName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// ValidateRemoteName checks that a remote name is usable as a directory
+// name and on the command line.
+func ValidateRemoteName(name string) error {
+	if !remoteName.MatchString(name) {
+		return fmt.Errorf("invalid remote name %q. Use letters, digits, '.', '-' and '_'", name)
+	}
+	return nil
+}
+
+// RemoteNames returns the configured remotes, sorted.
+func (c *Config) RemoteNames() []string {
+	var names []string
+	for name := range c.Remotes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// AddRemote adds a remote. The first remote becomes the default.
+func (c *Config) AddRemote(name, url string) error {
+	if err := ValidateRemoteName(name); err != nil {
+		return err
+	}
+	if _, ok := c.Remotes[name]; ok {
+		return fmt.Errorf("remote %s already exists", name)
+	}
+	if c.Remotes == nil {
+		c.Remotes = make(map[string]*Remote)
+	}
+	c.Remotes[name] = &Remote{URL: url}
+	if c.Remote == "" {
+		c.Remote = name
+	}
+	return nil
+}
+
+// RemoveRemote deletes a remote along with the graph IDs and upstreams
+// recorded for it.
+func (c *Config) RemoveRemote(name string) error {
+	if _, ok := c.Remotes[name]; !ok {
+		return fmt.Errorf("no such remote: %s", name)
+	}
+	delete(c.Remotes, name)
+	if c.Remote == name {
+		c.Remote = ""
+	}
+	for title, t := range c.Tracking {
+		delete(t.IDs, name)
+		if t.Upstream == name {
+			t.Upstream = ""
+		}
+		if t.Upstream == "" && len(t.IDs) == 0 {
+			delete(c.Tracking, title)
+		}
+	}
+	return nil
+}
+
+// RenameRemote renames a remote, keeping what is tracked on it.
+func (c *Config) RenameRemote(old, name string) error {
+	r, ok := c.Remotes[old]
+	if !ok {
+		return fmt.Errorf("no such remote: %s", old)
+	}
+	if err := ValidateRemoteName(name); err != nil {
+		return err
+	}
+	if _, ok := c.Remotes[name]; ok {
+		return fmt.Errorf("remote %s already exists", name)
+	}
+	delete(c.Remotes, old)
+	c.Remotes[name] = r
+	if c.Remote == old {
+		c.Remote = name
+	}
+	for _, t := range c.Tracking {
+		if id, ok := t.IDs[old]; ok {
+			delete(t.IDs, old)
+			t.IDs[name] = id
+		}
+		if t.Upstream == old {
+			t.Upstream = name
+		}
+	}
+	return nil
+}
+
+// SetRemoteURL points a remote at another registry.
+func (c *Config) SetRemoteURL(name, url string) error {
+	r, ok := c.Remotes[name]
+	if !ok {
+		return fmt.Errorf("no such remote: %s", name)
+	}
+	r.URL = url
+	return nil
+}
+
+// ResolveRemote picks the remote for a graph: the one named, else the
+// graph's upstream, else the default remote, else the only remote.
+func (c *Config) ResolveRemote(name, title string) (string, *Remote, error) {
+	if len(c.Remotes) == 0 {
+		return "", nil, fmt.Errorf("no remotes configured. Add one with 'tribal remote add <name> <url>'")
+	}
+	if name == "" {
+		if t := c.Tracking[title]; t != nil && t.Upstream != "" {
+			name = t.Upstream
+		} else if c.Remote != "" {
+			name = c.Remote
+		} else if len(c.Remotes) == 1 {
+			name = c.RemoteNames()[0]
+		} else {
+			return "", nil, fmt.Errorf("no upstream for %s and no default remote. Name a remote, one of: %v", title, c.RemoteNames())
+		}
+	}
+	r, ok := c.Remotes[name]
+	if !ok {
+		return "", nil, fmt.Errorf("no such remote: %s", name)
+	}
+	return name, r, nil
+}
+
+// TrackingFor returns the tracking record of a graph, creating it.
+func (c *Config) TrackingFor(title string) *Tracking {
+	if c.Tracking == nil {
+		c.Tracking = make(map[string]*Tracking)
+	}
+	t := c.Tracking[title]
+	if t == nil {
+		t = &Tracking{}
+		c.Tracking[title] = t
+	}
+	if t.IDs == nil {
+		t.IDs = make(map[string]string)
+	}
+	return t
+}
+
+// Credentials returns the login for a registry. The config's own registry
+// uses the resolved credentials, so TRIBAL_TOKEN applies to it; other
+// registries are looked up in the credential store, so a token is never
+// sent to a registry it was not issued for.
+func (c *Config) Credentials(registry string) (credentials.Credential, error) {
+	if credentials.Key(registry) == credentials.Key(c.RegistryURL) {
+		return credentials.Credential{Token: c.Token, Username: c.Username, UserID: c.UserID}, nil
+	}
+	store, err := credentials.Open(c.CredentialStore)
+	if err != nil {
+		return credentials.Credential{}, err
+	}
+	cred, _, err := store.Get(registry)
+	return cred, err
+}
@@ -0,0 +1,184 @@
+// Package credentials stores registry credentials outside repositories, in
+// a per-user file readable only by its owner, keyed by registry URL.
+// Tokens can instead be kept in the OS keyring, falling back to the file
+// where no keyring is available.
+package credentials
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Backends select where tokens are kept.
+const (
+	BackendFile    = "file"
+	BackendKeyring = "keyring"
+)
+
+// FileName is the credentials file in the user's tribal config directory.
+const FileName = "credentials.json"
+
+// Credential is what a registry login yields.
+type Credential struct {
+	Token    string `json:"token,omitempty"`
+	Username string `json:"username,omitempty"`
+	UserID   string `json:"user_id,omitempty"`
+	// InKeyring records that the token is kept in the OS keyring.
+	InKeyring bool `json:"in_keyring,omitempty"`
+}
+
+// Empty reports whether the credential holds nothing.
+func (c Credential) Empty() bool {
+	return c.Token == "" && c.Username == "" && c.UserID == ""
+}
+
+type file struct {
+	Registries map[string]Credential `json:"registries"`
+}
+
+// Store reads and writes credentials.
+type Store struct {
+	path    string
+	keyring bool
+}
+
+// Path returns the location of the credentials file.
+func Path() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot locate user config directory: %w", err)
+	}
+	return filepath.Join(dir, "tribal", FileName), nil
+}
+
+// Open returns the store for a backend; "" means the file.
+func Open(backend string) (*Store, error) {
+	if backend != "" && backend != BackendFile && backend != BackendKeyring {
+		return nil, fmt.Errorf("unknown credential store %q. Use file or keyring", backend)
+	}
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+	return &Store{path: path, keyring: backend == BackendKeyring}, nil
+}
+
+// Key normalizes a registry URL so equivalent spellings share credentials.
+func Key(registry string) string {
+	registry = strings.TrimRight(strings.TrimSpace(registry), "/")
+	u, err := url.Parse(registry)
+	if err != nil || u.Host == "" {
+		return registry
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	return u.String()
+}
+
+// Get returns the credential stored for a registry.
+func (s *Store) Get(registry string) (Credential, bool, error) {
+	f, err := s.read()
+	if err != nil {
+		return Credential{}, false, err
+	}
+	c, ok := f.Registries[Key(registry)]
+	if !ok {
+		return Credential{}, false, nil
+	}
+	// A keyring entry removed behind our back leaves the login without a
+	// token rather than failing every command
+	if c.InKeyring {
+		c.Token, _ = keyringGet(Key(registry))
+	}
+	c.InKeyring = false
+	return c, true, nil
+}
+
+// Set stores the credential for a registry, replacing any previous one.
+// With the keyring backend the token goes to the keyring when one is
+// available and to the file otherwise.
+func (s *Store) Set(registry string, c Credential) error {
+	f, err := s.read()
+	if err != nil {
+		return err
+	}
+	key := Key(registry)
+	c.InKeyring = false
+	if s.keyring && c.Token != "" && keyringAvailable() {
+		if err := keyringSet(key, c.Token); err == nil {
+			c.Token, c.InKeyring = "", true
+		}
+	}
+	f.Registries[key] = c
+	return s.write(f)
+}
+
+// Delete removes the credential for a registry.
+func (s *Store) Delete(registry string) error {
+	f, err := s.read()
+	if err != nil {
+		return err
+	}
+	key := Key(registry)
+	c, ok := f.Registries[key]
+	if !ok {
+		return nil
+	}
+	if c.InKeyring {
+		keyringDelete(key)
+	}
+	delete(f.Registries, key)
+	return s.write(f)
+}
+
+// Registries lists the registries with stored credentials.
+func (s *Store) Registries() ([]string, error) {
+	f, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	var keys []string
+	for key := range f.Registries {
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func (s *Store) read() (*file, error) {
+	f := &file{Registries: make(map[string]Credential)}
+	data, err := ioutil.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return f, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read credentials: %w", err)
+	}
+	if err := json.Unmarshal(data, f); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", s.path, err)
+	}
+	if f.Registries == nil {
+		f.Registries = make(map[string]Credential)
+	}
+	return f, nil
+}
+
+// write saves the file with owner-only permissions, tightening them if the
+// file already existed with broader ones.
+func (s *Store) write(f *file) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(s.path), err)
+	}
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize credentials: %w", err)
+	}
+	if err := ioutil.WriteFile(s.path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write credentials: %w", err)
+	}
+	return os.Chmod(s.path, 0600)
+}
@@ -0,0 +1,66 @@
+package credentials
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// The OS keyring is reached through the platform's command-line tools:
+// security on macOS and secret-tool (libsecret) on Linux. Entries use the
+// service name "tribal" and the registry URL as the account.
+const keyringService = "tribal"
+
+func keyringAvailable() bool {
+	switch runtime.GOOS {
+	case "darwin":
+		_, err := exec.LookPath("security")
+		return err == nil
+	case "linux", "freebsd", "openbsd", "netbsd":
+		_, err := exec.LookPath("secret-tool")
+		return err == nil
+	}
+	return false
+}
+
+func keyringGet(account string) (string, error) {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("security", "find-generic-password", "-s", keyringService, "-a", account, "-w")
+	default:
+		cmd = exec.Command("secret-tool", "lookup", "service", keyringService, "registry", account)
+	}
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("no keyring entry for %s", account)
+	}
+	return strings.TrimRight(string(out), "\r\n"), nil
+}
+
+func keyringSet(account, token string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("security", "add-generic-password", "-U", "-s", keyringService, "-a", account, "-w", token)
+	default:
+		// secret-tool reads the secret from stdin, keeping it off the
+		// command line.
+		cmd = exec.Command("secret-tool", "store", "--label", "tribal registry "+account, "service", keyringService, "registry", account)
+		cmd.Stdin = strings.NewReader(token)
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%v: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+func keyringDelete(account string) {
+	switch runtime.GOOS {
+	case "darwin":
+		exec.Command("security", "delete-generic-password", "-s", keyringService, "-a", account).Run()
+	default:
+		exec.Command("secret-tool", "clear", "service", keyringService, "registry", account).Run()
+	}
+}
@@ -0,0 +1,166 @@
+// Package docs renders TRIBAL.md from text/template templates. Besides the
+// built-in template, templates are looked up by name in the directories of
+// $TRIBAL_TEMPLATE_PATH (for templates shared across an organization) and
+// then the user's config directory.
+package docs
+
+import (
+	_ "embed"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/tribal/tribal-cli/internal/graph"
+)
+
+//go:embed default.md.tmpl
+var defaultTemplate string
+
+// DefaultTemplate names the built-in template.
+const DefaultTemplate = "default"
+
+// templateExt is the extension of template files in template directories.
+const templateExt = ".md.tmpl"
+
+// ConventionsFile holds team conventions written by hand, included in
+// TRIBAL.md as {{.Conventions}}.
+var ConventionsFile = filepath.Join(".tribal", "conventions.md")
+
+// Data is what templates are rendered with.
+type Data struct {
+	// Project is the name of the repository directory.
+	Project     string
+	RegistryURL string
+	Graphs      []Graph
+	Conventions string
+}
+
+// Graph summarizes one local graph for the graph index.
+type Graph struct {
+	Title       string
+	Description string
+	File        string
+	Nodes       int
+	Edges       int
+}
+
+// Dirs returns the template directories, in lookup order.
+func Dirs() []string {
+	var dirs []string
+	for _, dir := range filepath.SplitList(os.Getenv("TRIBAL_TEMPLATE_PATH")) {
+		if dir != "" {
+			dirs = append(dirs, dir)
+		}
+	}
+	if dir, err := os.UserConfigDir(); err == nil {
+		dirs = append(dirs, filepath.Join(dir, "tribal", "templates"))
+	}
+	return dirs
+}
+
+// Names lists the templates available by name.
+func Names() []string {
+	seen := map[string]bool{DefaultTemplate: true}
+	names := []string{DefaultTemplate}
+	for _, dir := range Dirs() {
+		entries, err := ioutil.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			name := strings.TrimSuffix(e.Name(), templateExt)
+			if e.IsDir() || name == e.Name() || seen[name] {
+				continue
+			}
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names[1:])
+	return names
+}
+
+// Load returns the text of a template given by name or by path to a file.
+// An empty ref selects the built-in template.
+func Load(ref string) (string, error) {
+	if ref == "" || ref == DefaultTemplate {
+		return defaultTemplate, nil
+	}
+
+	if strings.ContainsAny(ref, `/\`) || strings.HasSuffix(ref, ".tmpl") || strings.HasSuffix(ref, ".md") {
+		data, err := ioutil.ReadFile(ref)
+		if err != nil {
+			return "", fmt.Errorf("failed to read template: %w", err)
+		}
+		return string(data), nil
+	}
+
+	for _, dir := range Dirs() {
+		data, err := ioutil.ReadFile(filepath.Join(dir, ref+templateExt))
+		if err == nil {
+			return string(data), nil
+		}
+		if !os.IsNotExist(err) {
+			return "", fmt.Errorf("failed to read template %s: %w", ref, err)
+		}
+	}
+	return "", fmt.Errorf("template %q not found. Available templates: %s", ref, strings.Join(Names(), ", "))
+}
+
+var funcs = template.FuncMap{
+	"join":  strings.Join,
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+	"trim":  strings.TrimSpace,
+	// oneline collapses text onto a single line, e.g. for list items.
+	"oneline": func(s string) string { return strings.Join(strings.Fields(s), " ") },
+}
+
+// Render executes a template. The result always ends with a newline.
+func Render(text string, data Data) (string, error) {
+	tmpl, err := template.New("TRIBAL.md").Funcs(funcs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid template: %w", err)
+	}
+
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("failed to render template: %w", err)
+	}
+	return strings.TrimRight(b.String(), "\n") + "\n", nil
+}
+
+// Collect gathers the template data for the repository in the current
+// directory.
+func Collect(registryURL string) (Data, error) {
+	data := Data{RegistryURL: registryURL}
+	if wd, err := os.Getwd(); err == nil {
+		data.Project = filepath.Base(wd)
+	}
+
+	files, err := graph.LoadAll()
+	if err != nil {
+		return data, err
+	}
+	for _, f := range files {
+		data.Graphs = append(data.Graphs, Graph{
+			Title:       f.Graph.Title,
+			Description: strings.TrimSpace(f.Graph.Description()),
+			File:        filepath.ToSlash(f.Path),
+			Nodes:       len(f.Graph.Nodes),
+			Edges:       len(f.Graph.Edges),
+		})
+	}
+
+	conventions, err := ioutil.ReadFile(ConventionsFile)
+	if err != nil && !os.IsNotExist(err) {
+		return data, fmt.Errorf("failed to read %s: %w", ConventionsFile, err)
+	}
+	data.Conventions = strings.TrimSpace(string(conventions))
+
+	return data, nil
+}
@@ -0,0 +1,82 @@
+package export
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tribal/tribal-cli/internal/graph"
+)
+
+// pointsPerInch converts node sizes, stored in points, to Graphviz inches.
+const pointsPerInch = 72
+
+// DOT renders a graph in Graphviz DOT. Graphs with any directed edge become a
+// digraph and their undirected edges use dir=none. Node positions are pinned
+// (for neato and fdp) when the graph has a layout.
+func DOT(g *graph.Graph) ([]byte, error) {
+	directed := false
+	for _, e := range g.Edges {
+		if e.Directed {
+			directed = true
+			break
+		}
+	}
+
+	kind, arrow := "graph", "--"
+	if directed {
+		kind, arrow = "digraph", "->"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s {\n", kind, dotQuote(g.Title))
+	fmt.Fprintf(&b, "  label=%s;\n", dotQuote(g.Title))
+	b.WriteString("  node [shape=box];\n")
+
+	layout := hasLayout(g)
+	for _, n := range g.Nodes {
+		attrs := []string{"label=" + dotQuote(label(n))}
+		if layout {
+			// Graphviz's y axis points up; graph positions grow downwards.
+			y := -n.Position.Y
+			if y == 0 {
+				y = 0 // avoid printing -0
+			}
+			attrs = append(attrs, fmt.Sprintf(`pos="%g,%g!"`, n.Position.X, y))
+		}
+		if n.Size != nil {
+			attrs = append(attrs,
+				fmt.Sprintf("width=%g", n.Size.Width/pointsPerInch),
+				fmt.Sprintf("height=%g", n.Size.Height/pointsPerInch),
+				"fixedsize=true")
+		}
+		if n.Markup != nil {
+			attrs = append(attrs, "tooltip="+dotQuote(*n.Markup))
+		}
+		fmt.Fprintf(&b, "  %s [%s];\n", dotQuote(n.ID), strings.Join(attrs, ", "))
+	}
+
+	for _, e := range g.Edges {
+		var attrs []string
+		if l := deref(e.Label); l != "" {
+			attrs = append(attrs, "label="+dotQuote(l))
+		}
+		if directed && !e.Directed {
+			attrs = append(attrs, "dir=none")
+		}
+		fmt.Fprintf(&b, "  %s %s %s", dotQuote(e.Source), arrow, dotQuote(e.Target))
+		if len(attrs) > 0 {
+			fmt.Fprintf(&b, " [%s]", strings.Join(attrs, ", "))
+		}
+		b.WriteString(";\n")
+	}
+
+	b.WriteString("}\n")
+	return []byte(b.String()), nil
+}
+
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return `"` + s + `"`
+}
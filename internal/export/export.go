@@ -0,0 +1,152 @@
+package export
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/tribal/tribal-cli/internal/client"
+	"github.com/tribal/tribal-cli/internal/graph"
+)
+
+// Renderer turns a graph into a single document.
+type Renderer func(g *graph.Graph) ([]byte, error)
+
+var renderers = map[string]Renderer{
+	"dot":      DOT,
+	"mermaid":  Mermaid,
+	"plantuml": PlantUML,
+	"markdown": Markdown,
+	"html":     HTML,
+}
+
+var extensions = map[string]string{
+	"dot":      ".dot",
+	"mermaid":  ".mmd",
+	"plantuml": ".puml",
+	"markdown": ".md",
+	"html":     ".html",
+}
+
+// IndexFile is the table of contents written alongside a markdown export of
+// several graphs.
+const IndexFile = "README.md"
+
+// Formats lists the names accepted by Render, sorted.
+func Formats() []string {
+	names := make([]string, 0, len(renderers))
+	for name := range renderers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func Render(format string, g *graph.Graph) ([]byte, error) {
+	render, ok := renderers[strings.ToLower(format)]
+	if !ok {
+		return nil, fmt.Errorf("unknown format %q. Supported formats: %s", format, strings.Join(Formats(), ", "))
+	}
+	return render(g)
+}
+
+// FileName returns the name a graph is written to when exporting a directory.
+func FileName(g *graph.Graph, format string) string {
+	return strings.TrimSuffix(graph.Filename(g.Title), ".json") + extensions[strings.ToLower(format)]
+}
+
+// WriteAll renders every graph into dir, one file per graph, and returns the
+// paths written. Markdown exports also get an index linking the graphs.
+func WriteAll(format string, files []graph.File, dir string) ([]string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+
+	var written []string
+	for _, f := range files {
+		data, err := Render(format, f.Graph)
+		if err != nil {
+			return written, err
+		}
+
+		path := filepath.Join(dir, FileName(f.Graph, format))
+		if err := ioutil.WriteFile(path, data, 0644); err != nil {
+			return written, fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		written = append(written, path)
+	}
+
+	if strings.ToLower(format) == "markdown" {
+		path := filepath.Join(dir, IndexFile)
+		if err := ioutil.WriteFile(path, MarkdownIndex(files), 0644); err != nil {
+			return written, fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		written = append(written, path)
+	}
+
+	return written, nil
+}
+
+var unsafeIDChars = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// identifiers maps node IDs to identifiers made only of letters, digits and
+// underscores, as required by Mermaid and PlantUML. Identifiers matching a
+// reserved word, in any case, get a trailing underscore. Collisions are
+// resolved with a numeric suffix.
+func identifiers(nodes []client.Node, reserved map[string]bool) map[string]string {
+	ids := make(map[string]string)
+	used := make(map[string]bool)
+	for _, n := range nodes {
+		base := unsafeIDChars.ReplaceAllString(n.ID, "_")
+		if base == "" || (base[0] >= '0' && base[0] <= '9') {
+			base = "n" + base
+		}
+		if reserved[strings.ToLower(base)] {
+			base += "_"
+		}
+		id := base
+		for i := 2; used[id]; i++ {
+			id = fmt.Sprintf("%s_%d", base, i)
+		}
+		used[id] = true
+		ids[n.ID] = id
+	}
+	return ids
+}
+
+// nodeRef returns the identifier for a node, deriving one for edges that
+// reference nodes missing from the graph.
+func nodeRef(ids map[string]string, id string) string {
+	if ref, ok := ids[id]; ok {
+		return ref
+	}
+	return "missing_" + unsafeIDChars.ReplaceAllString(id, "_")
+}
+
+// hasLayout reports whether any node has been given a position.
+func hasLayout(g *graph.Graph) bool {
+	for _, n := range g.Nodes {
+		if n.Position.X != 0 || n.Position.Y != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func label(n client.Node) string {
+	if n.Label != "" {
+		return n.Label
+	}
+	return n.ID
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
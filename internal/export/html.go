@@ -0,0 +1,70 @@
+package export
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"html/template"
+
+	"github.com/tribal/tribal-cli/internal/graph"
+)
+
+//go:embed viewer.html
+var viewerHTML string
+
+var viewerTemplate = template.Must(template.New("viewer").Parse(viewerHTML))
+
+// Snapshot is one version of a graph selectable in the HTML viewer.
+type Snapshot struct {
+	Label   string       `json:"label"`
+	Message string       `json:"message,omitempty"`
+	Graph   *graph.Graph `json:"graph"`
+}
+
+// HTML renders a graph as a single self-contained HTML page with an
+// interactive, pannable diagram. It needs no network access to view.
+func HTML(g *graph.Graph) ([]byte, error) {
+	return HTMLHistory([]Snapshot{{Label: g.Title, Graph: g}})
+}
+
+// HTMLHistory renders a viewer page with a selector for switching between
+// snapshots, such as the working copy and each commit of a graph. The first
+// snapshot is shown initially.
+func HTMLHistory(snapshots []Snapshot) ([]byte, error) {
+	if len(snapshots) == 0 {
+		return nil, fmt.Errorf("nothing to export")
+	}
+
+	var b bytes.Buffer
+	err := viewerTemplate.Execute(&b, struct {
+		Title     string
+		Snapshots []Snapshot
+	}{snapshots[0].Graph.Title, snapshots})
+	if err != nil {
+		return nil, fmt.Errorf("failed to render HTML: %w", err)
+	}
+
+	return b.Bytes(), nil
+}
+
+// CommitSnapshots returns a snapshot for every commit of the graph with the
+// given title, newest first.
+func CommitSnapshots(title string, commits []*graph.Commit) []Snapshot {
+	var snapshots []Snapshot
+	for i := len(commits) - 1; i >= 0; i-- {
+		c := commits[i]
+		if c.Graph.Title != title {
+			continue
+		}
+		label := fmt.Sprintf("%s: %s (%s)", c.ID, c.Message, c.Timestamp)
+		if sha := c.GitCommit(); len(sha) >= 7 {
+			label += " git " + sha[:7]
+		}
+		snapshots = append(snapshots, Snapshot{
+			Label:   label,
+			Message: c.Message,
+			Graph:   c.Graph,
+		})
+	}
+	return snapshots
+}
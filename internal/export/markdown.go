@@ -0,0 +1,131 @@
+package export
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tribal/tribal-cli/internal/anchor"
+	"github.com/tribal/tribal-cli/internal/client"
+	"github.com/tribal/tribal-cli/internal/graph"
+)
+
+// Markdown renders a graph as a markdown document: a table of contents, an
+// embedded Mermaid diagram, and one section per node containing its markup
+// and links to the nodes it connects to.
+func Markdown(g *graph.Graph) ([]byte, error) {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# %s\n\n", markdownText(g.Title))
+	if description := g.Description(); description != "" {
+		fmt.Fprintf(&b, "%s\n\n", description)
+	}
+	fmt.Fprintf(&b, "%d nodes, %d edges.\n\n", len(g.Nodes), len(g.Edges))
+
+	b.WriteString("## Contents\n\n")
+	b.WriteString("- [Diagram](#diagram)\n")
+	b.WriteString("- [Nodes](#nodes)\n")
+	for _, n := range g.Nodes {
+		fmt.Fprintf(&b, "  - [%s](#%s)\n", markdownText(label(n)), nodeAnchor(n.ID))
+	}
+	b.WriteString("\n")
+
+	diagram, err := Mermaid(g)
+	if err != nil {
+		return nil, err
+	}
+	b.WriteString("## Diagram\n\n```mermaid\n")
+	b.Write(diagram)
+	b.WriteString("```\n\n")
+
+	b.WriteString("## Nodes\n")
+	for _, n := range g.Nodes {
+		writeMarkdownNode(&b, g, n)
+	}
+
+	return []byte(b.String()), nil
+}
+
+func writeMarkdownNode(b *strings.Builder, g *graph.Graph, n client.Node) {
+	fmt.Fprintf(b, "\n<a id=\"%s\"></a>\n\n### %s\n\n", nodeAnchor(n.ID), markdownText(label(n)))
+	fmt.Fprintf(b, "ID: `%s`\n\n", n.ID)
+
+	if markup := strings.TrimSpace(deref(n.Markup)); markup != "" {
+		fmt.Fprintf(b, "%s\n\n", markup)
+	} else {
+		b.WriteString("_No description._\n\n")
+	}
+
+	if len(n.Anchors) > 0 {
+		b.WriteString("**Code**\n\n")
+		for _, a := range n.Anchors {
+			fmt.Fprintf(b, "- `%s`\n", anchor.String(a))
+		}
+		b.WriteString("\n")
+	}
+
+	groups := []struct {
+		title     string
+		direction graph.Direction
+	}{
+		{"Outgoing", graph.Outgoing},
+		{"Incoming", graph.Incoming},
+		{"Connected", graph.Undirected},
+	}
+
+	neighbors := g.Neighbors(n.ID)
+	for _, group := range groups {
+		var lines []string
+		for _, nb := range neighbors {
+			if nb.Direction != group.direction {
+				continue
+			}
+			line := fmt.Sprintf("- [%s](#%s)", markdownText(label(nb.Node)), nodeAnchor(nb.Node.ID))
+			if l := deref(nb.Edge.Label); l != "" {
+				line = fmt.Sprintf("- _%s_ %s", markdownText(l), line[2:])
+			}
+			if markup := strings.TrimSpace(deref(nb.Edge.Markup)); markup != "" {
+				line += ": " + strings.Join(strings.Fields(markup), " ")
+			}
+			lines = append(lines, line)
+		}
+		if len(lines) == 0 {
+			continue
+		}
+		fmt.Fprintf(b, "**%s**\n\n%s\n\n", group.title, strings.Join(lines, "\n"))
+	}
+}
+
+// MarkdownIndex renders a table of contents for a directory of markdown
+// exports, linking each graph to its file.
+func MarkdownIndex(files []graph.File) []byte {
+	var b strings.Builder
+	b.WriteString("# Graphs\n\n")
+	b.WriteString("Generated by `tribal export --format markdown`. Do not edit by hand.\n\n")
+
+	if len(files) == 0 {
+		b.WriteString("No graphs.\n")
+		return []byte(b.String())
+	}
+
+	b.WriteString("| Graph | Nodes | Edges | Description |\n")
+	b.WriteString("| --- | --- | --- | --- |\n")
+	for _, f := range files {
+		fmt.Fprintf(&b, "| [%s](%s) | %d | %d | %s |\n",
+			markdownText(f.Graph.Title), FileName(f.Graph, "markdown"),
+			len(f.Graph.Nodes), len(f.Graph.Edges),
+			strings.ReplaceAll(strings.Join(strings.Fields(f.Graph.Description()), " "), "|", `\|`))
+	}
+
+	return []byte(b.String())
+}
+
+func nodeAnchor(id string) string {
+	return "node-" + unsafeIDChars.ReplaceAllString(id, "-")
+}
+
+// markdownText escapes characters that would otherwise start markdown
+// formatting inside headings and link text.
+func markdownText(s string) string {
+	replacer := strings.NewReplacer(`[`, `\[`, `]`, `\]`, `*`, `\*`, `_`, `\_`, "`", "\\`", "\n", " ")
+	return replacer.Replace(s)
+}
@@ -0,0 +1,61 @@
+package export
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/tribal/tribal-cli/internal/graph"
+)
+
+// mermaidKeywords are words that cannot be used as Mermaid flowchart node IDs.
+var mermaidKeywords = map[string]bool{
+	"end":      true,
+	"subgraph": true,
+	"graph":    true,
+	"style":    true,
+	"class":    true,
+	"click":    true,
+}
+
+// Mermaid renders a graph as a Mermaid flowchart. Mermaid lays diagrams out
+// itself, so node positions and sizes are not exported.
+func Mermaid(g *graph.Graph) ([]byte, error) {
+	ids := identifiers(g.Nodes, mermaidKeywords)
+
+	var b strings.Builder
+	if g.Title != "" {
+		fmt.Fprintf(&b, "---\ntitle: %s\n---\n", mermaidTitle(g.Title))
+	}
+	b.WriteString("flowchart LR\n")
+
+	for _, n := range g.Nodes {
+		fmt.Fprintf(&b, "  %s[\"%s\"]\n", ids[n.ID], mermaidText(label(n)))
+	}
+
+	for _, e := range g.Edges {
+		source, target := nodeRef(ids, e.Source), nodeRef(ids, e.Target)
+		link := "---"
+		if e.Directed {
+			link = "-->"
+		}
+		if l := deref(e.Label); l != "" {
+			fmt.Fprintf(&b, "  %s %s|\"%s\"| %s\n", source, link, mermaidText(l), target)
+		} else {
+			fmt.Fprintf(&b, "  %s %s %s\n", source, link, target)
+		}
+	}
+
+	return []byte(b.String()), nil
+}
+
+// mermaidTitle quotes a title for the YAML front matter, so that characters
+// such as ':' and '#' are read as part of it.
+func mermaidTitle(s string) string {
+	return strconv.Quote(strings.Join(strings.Fields(s), " "))
+}
+
+func mermaidText(s string) string {
+	s = strings.ReplaceAll(s, `"`, "#quot;")
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,48 @@
+package export
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tribal/tribal-cli/internal/graph"
+)
+
+// PlantUML renders a graph as a PlantUML component diagram with one rectangle
+// per node. PlantUML has no way to place nodes, so positions are not exported.
+func PlantUML(g *graph.Graph) ([]byte, error) {
+	ids := identifiers(g.Nodes, nil)
+
+	var b strings.Builder
+	b.WriteString("@startuml\n")
+	if g.Title != "" {
+		fmt.Fprintf(&b, "title %s\n", plantUMLText(g.Title))
+	}
+	b.WriteString("\n")
+
+	for _, n := range g.Nodes {
+		fmt.Fprintf(&b, "rectangle \"%s\" as %s\n", plantUMLText(label(n)), ids[n.ID])
+	}
+	if len(g.Nodes) > 0 {
+		b.WriteString("\n")
+	}
+
+	for _, e := range g.Edges {
+		link := "--"
+		if e.Directed {
+			link = "-->"
+		}
+		fmt.Fprintf(&b, "%s %s %s", nodeRef(ids, e.Source), link, nodeRef(ids, e.Target))
+		if l := deref(e.Label); l != "" {
+			fmt.Fprintf(&b, " : %s", plantUMLText(l))
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("@enduml\n")
+	return []byte(b.String()), nil
+}
+
+func plantUMLText(s string) string {
+	s = strings.ReplaceAll(s, `"`, `'`)
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,119 @@
+// Package git runs the git command line for the parts of tribal that
+// correlate graphs with the surrounding code repository.
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Run runs git in dir and returns its trimmed standard output.
+func Run(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return "", fmt.Errorf("git %s: %s", args[0], msg)
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// Available reports whether git is installed and dir is inside a work tree.
+func Available(dir string) bool {
+	if _, err := exec.LookPath("git"); err != nil {
+		return false
+	}
+	out, err := Run(dir, "rev-parse", "--is-inside-work-tree")
+	return err == nil && out == "true"
+}
+
+// Head returns the SHA of the checked out commit.
+func Head(dir string) (string, error) {
+	return Run(dir, "rev-parse", "HEAD")
+}
+
+// Commit is one entry of git log.
+type Commit struct {
+	SHA     string
+	Subject string
+}
+
+// Log lists the commits since a revision that touched path, newest first.
+func Log(dir, since, path string) ([]Commit, error) {
+	out, err := Run(dir, "log", "--format=%h %s", since+"..HEAD", "--", path)
+	if err != nil {
+		return nil, err
+	}
+
+	var commits []Commit
+	for _, line := range strings.Split(out, "\n") {
+		if line == "" {
+			continue
+		}
+		sha, subject, _ := strings.Cut(line, " ")
+		commits = append(commits, Commit{SHA: sha, Subject: subject})
+	}
+	return commits, nil
+}
+
+// Renamed returns the path a file was renamed to between a revision and the
+// working tree, or "" if git saw no rename.
+func Renamed(dir, since, path string) (string, error) {
+	out, err := Run(dir, "diff", "--name-status", "--relative", "-M", since)
+	if err != nil {
+		return "", err
+	}
+
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) == 3 && strings.HasPrefix(fields[0], "R") && fields[1] == path {
+			return fields[2], nil
+		}
+	}
+	return "", nil
+}
+
+// HooksDir returns the directory git runs hooks from, honouring
+// core.hooksPath.
+func HooksDir(dir string) (string, error) {
+	out, err := Run(dir, "rev-parse", "--git-path", "hooks")
+	if err != nil {
+		return "", err
+	}
+	if !filepath.IsAbs(out) {
+		out = filepath.Join(dir, out)
+	}
+	return out, nil
+}
+
+// Prefix returns the path of dir relative to the top of its work tree, with
+// a trailing slash, or "" at the top.
+func Prefix(dir string) (string, error) {
+	return Run(dir, "rev-parse", "--show-prefix")
+}
+
+// Message returns the full message of a commit.
+func Message(dir, rev string) (string, error) {
+	return Run(dir, "log", "-1", "--format=%B", rev)
+}
+
+// ChangedFiles lists the files under path, relative to dir, that a commit
+// changed.
+func ChangedFiles(dir, rev, path string) ([]string, error) {
+	out, err := Run(dir, "diff-tree", "--no-commit-id", "--name-only", "--relative", "-r", "--root", rev, "--", path)
+	if err != nil {
+		return nil, err
+	}
+	if out == "" {
+		return nil, nil
+	}
+	return strings.Split(out, "\n"), nil
+}
@@ -0,0 +1,45 @@
+package git
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tribal/tribal-cli/internal/managed"
+)
+
+// InstallHook writes body into the managed block of a hook script, creating
+// the script if needed. Content outside the block, such as hooks installed
+// by other tools, is preserved. It returns the path of the hook.
+func InstallHook(dir, name, body string) (string, error) {
+	hooksDir, err := HooksDir(dir)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(hooksDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create hooks directory: %w", err)
+	}
+
+	path := filepath.Join(hooksDir, name)
+	existing, err := ioutil.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return "", fmt.Errorf("failed to read %s hook: %w", name, err)
+	}
+
+	content := string(existing)
+	if strings.TrimSpace(content) == "" {
+		content = "#!/bin/sh\n"
+	}
+	content = managed.Apply(content, body, managed.ShellMarkers, false)
+
+	if err := ioutil.WriteFile(path, []byte(content), 0755); err != nil {
+		return "", fmt.Errorf("failed to write %s hook: %w", name, err)
+	}
+	// WriteFile keeps the mode of an existing file.
+	if err := os.Chmod(path, 0755); err != nil {
+		return "", err
+	}
+	return path, nil
+}
@@ -0,0 +1,405 @@
+package graph
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/tribal/tribal-cli/internal/client"
+)
+
+// Direction describes how an edge relates to the node it was found from.
+type Direction string
+
+const (
+	Outgoing   Direction = "out"
+	Incoming   Direction = "in"
+	Undirected Direction = "undirected"
+)
+
+// Neighbor is a node adjacent to another, with the edge that connects them.
+type Neighbor struct {
+	Node      client.Node
+	Edge      client.Edge
+	Direction Direction
+}
+
+// Degree counts the edges attached to a node.
+type Degree struct {
+	Node       client.Node
+	In         int
+	Out        int
+	Undirected int
+}
+
+func (d Degree) Total() int {
+	return d.In + d.Out + d.Undirected
+}
+
+// Resolve finds a node by ID, or failing that by case-insensitive label.
+func (g *Graph) Resolve(ref string) (*client.Node, error) {
+	if n := g.Node(ref); n != nil {
+		return n, nil
+	}
+
+	var found []*client.Node
+	for i := range g.Nodes {
+		if strings.EqualFold(g.Nodes[i].Label, ref) {
+			found = append(found, &g.Nodes[i])
+		}
+	}
+
+	switch len(found) {
+	case 0:
+		return nil, fmt.Errorf("no node with ID or label %q in graph %s", ref, g.Title)
+	case 1:
+		return found[0], nil
+	}
+
+	ids := make([]string, len(found))
+	for i, n := range found {
+		ids[i] = n.ID
+	}
+	return nil, fmt.Errorf("label %q is ambiguous, matching nodes %s. Use a node ID", ref, strings.Join(ids, ", "))
+}
+
+// Neighbors returns every node connected to id, in edge order.
+func (g *Graph) Neighbors(id string) []Neighbor {
+	var neighbors []Neighbor
+	for _, e := range g.Edges {
+		var other string
+		var dir Direction
+		switch {
+		case e.Source == id:
+			other, dir = e.Target, Outgoing
+		case e.Target == id:
+			other, dir = e.Source, Incoming
+		default:
+			continue
+		}
+		if !e.Directed {
+			dir = Undirected
+		}
+
+		n := g.Node(other)
+		if n == nil {
+			continue
+		}
+		neighbors = append(neighbors, Neighbor{Node: *n, Edge: e, Direction: dir})
+	}
+	return neighbors
+}
+
+// Neighborhood returns the subgraph of nodes within depth edges of id,
+// ignoring edge direction, with every edge between them.
+func (g *Graph) Neighborhood(id string, depth int) *Graph {
+	distance := map[string]int{id: 0}
+	queue := []string{id}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		if distance[cur] == depth {
+			continue
+		}
+		for _, nb := range g.Neighbors(cur) {
+			if _, seen := distance[nb.Node.ID]; !seen {
+				distance[nb.Node.ID] = distance[cur] + 1
+				queue = append(queue, nb.Node.ID)
+			}
+		}
+	}
+
+	sub := &Graph{ID: g.ID, Title: g.Title, Metadata: g.Metadata}
+	for _, n := range g.Nodes {
+		if _, ok := distance[n.ID]; ok {
+			sub.Nodes = append(sub.Nodes, n)
+		}
+	}
+	for _, e := range g.Edges {
+		_, okS := distance[e.Source]
+		_, okT := distance[e.Target]
+		if okS && okT {
+			sub.Edges = append(sub.Edges, e)
+		}
+	}
+	return sub
+}
+
+// ShortestPath returns the edges along a shortest path from one node to
+// another. Directed edges are only followed from source to target. It returns
+// false when no path exists.
+func (g *Graph) ShortestPath(from, to string) ([]client.Edge, bool) {
+	if from == to {
+		return nil, true
+	}
+
+	type step struct {
+		parent string
+		edge   int
+	}
+	visited := map[string]step{from: {edge: -1}}
+	queue := []string{from}
+
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+
+		for i, e := range g.Edges {
+			var next string
+			switch {
+			case e.Source == id:
+				next = e.Target
+			case e.Target == id && !e.Directed:
+				next = e.Source
+			default:
+				continue
+			}
+			if _, seen := visited[next]; seen {
+				continue
+			}
+			visited[next] = step{parent: id, edge: i}
+
+			if next == to {
+				var path []client.Edge
+				for cur := to; cur != from; cur = visited[cur].parent {
+					path = append([]client.Edge{g.Edges[visited[cur].edge]}, path...)
+				}
+				return path, true
+			}
+			queue = append(queue, next)
+		}
+	}
+
+	return nil, false
+}
+
+// directedAdjacency maps each node ID to the targets of its directed edges.
+// Undirected edges are ignored.
+func (g *Graph) directedAdjacency() map[string][]string {
+	adj := make(map[string][]string)
+	for _, e := range g.Edges {
+		if e.Directed && g.Node(e.Source) != nil && g.Node(e.Target) != nil {
+			adj[e.Source] = append(adj[e.Source], e.Target)
+		}
+	}
+	return adj
+}
+
+// Cycles returns one cycle, as a list of node IDs, for every strongly
+// connected group of nodes in the directed part of the graph, including
+// self-loops.
+func (g *Graph) Cycles() [][]string {
+	adj := g.directedAdjacency()
+
+	var cycles [][]string
+	for _, scc := range g.stronglyConnected(adj) {
+		if len(scc) == 1 && !contains(adj[scc[0]], scc[0]) {
+			continue
+		}
+		cycles = append(cycles, findCycle(scc, adj))
+	}
+	return cycles
+}
+
+// stronglyConnected implements Tarjan's algorithm, returning components in
+// graph node order.
+func (g *Graph) stronglyConnected(adj map[string][]string) [][]string {
+	index := make(map[string]int)
+	low := make(map[string]int)
+	onStack := make(map[string]bool)
+	var stack []string
+	var components [][]string
+	counter := 0
+
+	var visit func(id string)
+	visit = func(id string) {
+		index[id] = counter
+		low[id] = counter
+		counter++
+		stack = append(stack, id)
+		onStack[id] = true
+
+		for _, next := range adj[id] {
+			if _, seen := index[next]; !seen {
+				visit(next)
+				if low[next] < low[id] {
+					low[id] = low[next]
+				}
+			} else if onStack[next] && index[next] < low[id] {
+				low[id] = index[next]
+			}
+		}
+
+		if low[id] == index[id] {
+			var component []string
+			for {
+				top := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[top] = false
+				component = append(component, top)
+				if top == id {
+					break
+				}
+			}
+			components = append(components, component)
+		}
+	}
+
+	for _, n := range g.Nodes {
+		if _, seen := index[n.ID]; !seen {
+			visit(n.ID)
+		}
+	}
+
+	return components
+}
+
+// findCycle walks from the first node of a strongly connected component,
+// staying inside it, until it returns to a node already on the path.
+func findCycle(scc []string, adj map[string][]string) []string {
+	members := make(map[string]bool)
+	for _, id := range scc {
+		members[id] = true
+	}
+
+	start := scc[len(scc)-1]
+	position := map[string]int{start: 0}
+	path := []string{start}
+	for {
+		cur := path[len(path)-1]
+		for _, next := range adj[cur] {
+			if !members[next] {
+				continue
+			}
+			if i, seen := position[next]; seen {
+				return append(path[i:], next)
+			}
+			position[next] = len(path)
+			path = append(path, next)
+			break
+		}
+	}
+}
+
+// Components returns the weakly connected components of the graph, largest
+// first. Edge direction is ignored.
+func (g *Graph) Components() [][]client.Node {
+	parent := make(map[string]string)
+	var find func(string) string
+	find = func(id string) string {
+		if parent[id] != id {
+			parent[id] = find(parent[id])
+		}
+		return parent[id]
+	}
+
+	for _, n := range g.Nodes {
+		parent[n.ID] = n.ID
+	}
+	for _, e := range g.Edges {
+		if _, ok := parent[e.Source]; !ok {
+			continue
+		}
+		if _, ok := parent[e.Target]; !ok {
+			continue
+		}
+		parent[find(e.Source)] = find(e.Target)
+	}
+
+	groups := make(map[string]int)
+	var components [][]client.Node
+	for _, n := range g.Nodes {
+		root := find(n.ID)
+		i, ok := groups[root]
+		if !ok {
+			i = len(components)
+			groups[root] = i
+			components = append(components, nil)
+		}
+		components[i] = append(components[i], n)
+	}
+
+	sort.SliceStable(components, func(i, j int) bool {
+		return len(components[i]) > len(components[j])
+	})
+
+	return components
+}
+
+// TopologicalOrder orders nodes so that every directed edge points forward.
+// Undirected edges are ignored. It fails if the directed edges form a cycle.
+func (g *Graph) TopologicalOrder() ([]client.Node, error) {
+	adj := g.directedAdjacency()
+	inDegree := make(map[string]int)
+	for _, targets := range adj {
+		for _, t := range targets {
+			inDegree[t]++
+		}
+	}
+
+	var queue []string
+	for _, n := range g.Nodes {
+		if inDegree[n.ID] == 0 {
+			queue = append(queue, n.ID)
+		}
+	}
+
+	var order []client.Node
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		order = append(order, *g.Node(id))
+
+		for _, t := range adj[id] {
+			inDegree[t]--
+			if inDegree[t] == 0 {
+				queue = append(queue, t)
+			}
+		}
+	}
+
+	if len(order) < len(g.Nodes) {
+		return nil, fmt.Errorf("graph contains %d cycle(s); run 'tribal graph cycles' for details", len(g.Cycles()))
+	}
+
+	return order, nil
+}
+
+// Degrees returns edge counts for every node, in graph order.
+func (g *Graph) Degrees() []Degree {
+	degrees := make([]Degree, len(g.Nodes))
+	index := make(map[string]int)
+	for i, n := range g.Nodes {
+		degrees[i].Node = n
+		index[n.ID] = i
+	}
+
+	for _, e := range g.Edges {
+		if !e.Directed {
+			if i, ok := index[e.Source]; ok {
+				degrees[i].Undirected++
+			}
+			if i, ok := index[e.Target]; ok && e.Target != e.Source {
+				degrees[i].Undirected++
+			}
+			continue
+		}
+		if i, ok := index[e.Source]; ok {
+			degrees[i].Out++
+		}
+		if i, ok := index[e.Target]; ok {
+			degrees[i].In++
+		}
+	}
+
+	return degrees
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
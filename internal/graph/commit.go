@@ -0,0 +1,158 @@
+package graph
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tribal/tribal-cli/internal/config"
+	"github.com/tribal/tribal-cli/internal/git"
+)
+
+// Commit is a graph snapshot written by 'tribal commit'. Metadata holds
+// git_commit, the SHA of the code commit the snapshot was taken at, when the
+// repository is inside a git work tree.
+type Commit struct {
+	ID        string                 `json:"id"`
+	Message   string                 `json:"message"`
+	Timestamp string                 `json:"timestamp"`
+	Author    string                 `json:"author"`
+	Graph     *Graph                 `json:"graph"`
+	Metadata  map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// GitCommit returns the git SHA recorded with the commit, or "".
+func (c *Commit) GitCommit() string {
+	sha, _ := c.Metadata["git_commit"].(string)
+	return sha
+}
+
+// CommitGraph writes a snapshot of g as a new commit and records it as the
+// latest commit in the config, clearing anything staged. It is the
+// non-interactive equivalent of 'tribal add -A' followed by 'tribal commit'.
+func CommitGraph(g *Graph, message, author string) (*Commit, string, error) {
+	if err := os.MkdirAll(CommitsDir(), 0755); err != nil {
+		return nil, "", fmt.Errorf("failed to create commits directory: %w", err)
+	}
+
+	now := time.Now()
+	c := &Commit{
+		ID:        fmt.Sprintf("commit_%d", now.Unix()),
+		Message:   message,
+		Timestamp: now.Format(time.RFC3339),
+		Author:    author,
+		Graph:     g,
+	}
+	// Commit IDs have one-second resolution; keep quick successive
+	// commits apart.
+	path := filepath.Join(CommitsDir(), c.ID+".json")
+	for i := 2; fileExists(path); i++ {
+		c.ID = fmt.Sprintf("commit_%d_%d", now.Unix(), i)
+		path = filepath.Join(CommitsDir(), c.ID+".json")
+	}
+	if git.Available(".") {
+		if head, err := git.Head("."); err == nil {
+			c.Metadata = map[string]interface{}{"git_commit": head}
+		}
+	}
+
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to serialize commit: %w", err)
+	}
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		return nil, "", fmt.Errorf("failed to save commit: %w", err)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, "", err
+	}
+	cfg.LatestCommit = c.ID
+	cfg.LatestCommitFile = path
+	cfg.StagedGraph = ""
+	cfg.StagedGraphFile = ""
+	if err := cfg.Save(); err != nil {
+		return nil, "", fmt.Errorf("failed to update config: %w", err)
+	}
+
+	return c, path, nil
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func CommitsDir() string {
+	return filepath.Join(config.ConfigDir, "commits")
+}
+
+// LoadCommit reads a commit by ID or by path to its file.
+func LoadCommit(ref string) (*Commit, error) {
+	path := ref
+	if !strings.HasSuffix(ref, ".json") {
+		path = filepath.Join(CommitsDir(), ref+".json")
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("commit not found: %s", ref)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commit: %w", err)
+	}
+
+	var c Commit
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("failed to parse commit %s: %w", path, err)
+	}
+	if c.Graph == nil {
+		return nil, fmt.Errorf("commit %s has no graph", c.ID)
+	}
+	if c.Graph.Metadata == nil {
+		c.Graph.Metadata = make(map[string]interface{})
+	}
+
+	return &c, nil
+}
+
+// ListCommits returns every commit, oldest first. Commits that cannot be
+// parsed are skipped.
+func ListCommits() ([]*Commit, error) {
+	dir := CommitsDir()
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commits directory: %w", err)
+	}
+
+	var commits []*Commit
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		c, err := LoadCommit(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			continue
+		}
+		commits = append(commits, c)
+	}
+
+	sort.SliceStable(commits, func(i, j int) bool {
+		if commits[i].Timestamp != commits[j].Timestamp {
+			return commits[i].Timestamp < commits[j].Timestamp
+		}
+		return commits[i].ID < commits[j].ID
+	})
+
+	return commits, nil
+}
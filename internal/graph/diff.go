@@ -0,0 +1,100 @@
+package graph
+
+import (
+	"reflect"
+
+	"github.com/tribal/tribal-cli/internal/client"
+)
+
+// NodeChange is a node that exists in both versions of a graph but differs.
+type NodeChange struct {
+	Before client.Node `json:"before"`
+	After  client.Node `json:"after"`
+}
+
+// EdgeChange is an edge that exists in both versions of a graph but differs.
+type EdgeChange struct {
+	Before client.Edge `json:"before"`
+	After  client.Edge `json:"after"`
+}
+
+// Changes lists the differences between two versions of a graph, matching
+// nodes and edges by ID.
+type Changes struct {
+	TitleChanged bool          `json:"title_changed,omitempty"`
+	NodesAdded   []client.Node `json:"nodes_added"`
+	NodesRemoved []client.Node `json:"nodes_removed"`
+	NodesChanged []NodeChange  `json:"nodes_changed"`
+	EdgesAdded   []client.Edge `json:"edges_added"`
+	EdgesRemoved []client.Edge `json:"edges_removed"`
+	EdgesChanged []EdgeChange  `json:"edges_changed"`
+}
+
+// Empty reports whether the two versions are the same.
+func (c Changes) Empty() bool {
+	return !c.TitleChanged && len(c.NodesAdded) == 0 && len(c.NodesRemoved) == 0 && len(c.NodesChanged) == 0 &&
+		len(c.EdgesAdded) == 0 && len(c.EdgesRemoved) == 0 && len(c.EdgesChanged) == 0
+}
+
+// Diff compares two versions of a graph. A nil before is treated as empty.
+func Diff(before, after *Graph) Changes {
+	if before == nil {
+		before = &Graph{Title: after.Title}
+	}
+	c := Changes{
+		TitleChanged: before.Title != after.Title,
+		NodesAdded:   []client.Node{},
+		NodesRemoved: []client.Node{},
+		NodesChanged: []NodeChange{},
+		EdgesAdded:   []client.Edge{},
+		EdgesRemoved: []client.Edge{},
+		EdgesChanged: []EdgeChange{},
+	}
+
+	for _, n := range after.Nodes {
+		old := before.Node(n.ID)
+		switch {
+		case old == nil:
+			c.NodesAdded = append(c.NodesAdded, n)
+		case !reflect.DeepEqual(*old, n):
+			c.NodesChanged = append(c.NodesChanged, NodeChange{Before: *old, After: n})
+		}
+	}
+	for _, n := range before.Nodes {
+		if after.Node(n.ID) == nil {
+			c.NodesRemoved = append(c.NodesRemoved, n)
+		}
+	}
+
+	for _, e := range after.Edges {
+		old := before.Edge(e.ID)
+		switch {
+		case old == nil:
+			c.EdgesAdded = append(c.EdgesAdded, e)
+		case !reflect.DeepEqual(*old, e):
+			c.EdgesChanged = append(c.EdgesChanged, EdgeChange{Before: *old, After: e})
+		}
+	}
+	for _, e := range before.Edges {
+		if after.Edge(e.ID) == nil {
+			c.EdgesRemoved = append(c.EdgesRemoved, e)
+		}
+	}
+
+	return c
+}
+
+// LatestCommit returns the most recent commit of the graph with the given
+// title, or nil if it has never been committed.
+func LatestCommit(title string) (*Commit, error) {
+	commits, err := ListCommits()
+	if err != nil {
+		return nil, err
+	}
+	for i := len(commits) - 1; i >= 0; i-- {
+		if commits[i].Graph.Title == title {
+			return commits[i], nil
+		}
+	}
+	return nil, nil
+}
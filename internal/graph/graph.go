@@ -0,0 +1,264 @@
+package graph
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tribal/tribal-cli/internal/client"
+	"github.com/tribal/tribal-cli/internal/config"
+)
+
+// Graph is the local representation of a graph stored under .tribal/graphs.
+type Graph struct {
+	// ID is the registry ID of the graph, set once it exists remotely.
+	ID       string                 `json:"id,omitempty"`
+	Title    string                 `json:"title"`
+	Nodes    []client.Node          `json:"nodes"`
+	Edges    []client.Edge          `json:"edges"`
+	Metadata map[string]interface{} `json:"metadata"`
+}
+
+// File pairs a loaded graph with the path it was read from.
+type File struct {
+	Path  string
+	Graph *Graph
+}
+
+func Dir() string {
+	return filepath.Join(config.ConfigDir, "graphs")
+}
+
+// Filename returns the file name used to store a graph with the given title.
+func Filename(title string) string {
+	return strings.ReplaceAll(strings.ToLower(title), " ", "_") + ".json"
+}
+
+func PathFor(title string) string {
+	return filepath.Join(Dir(), Filename(title))
+}
+
+func Load(path string) (*Graph, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read graph: %w", err)
+	}
+
+	var g Graph
+	if err := json.Unmarshal(data, &g); err != nil {
+		return nil, fmt.Errorf("failed to parse graph %s: %w", path, err)
+	}
+
+	if g.Metadata == nil {
+		g.Metadata = make(map[string]interface{})
+	}
+
+	return &g, nil
+}
+
+func (g *Graph) Save(path string) error {
+	if g.Nodes == nil {
+		g.Nodes = []client.Node{}
+	}
+	if g.Edges == nil {
+		g.Edges = []client.Edge{}
+	}
+
+	data, err := json.MarshalIndent(g, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize graph: %w", err)
+	}
+
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write graph: %w", err)
+	}
+
+	return nil
+}
+
+// LoadAll reads every graph in the graphs directory, sorted by file name.
+// Files that cannot be parsed are skipped.
+func LoadAll() ([]File, error) {
+	dir := Dir()
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read graphs directory: %w", err)
+	}
+
+	var files []File
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		g, err := Load(path)
+		if err != nil {
+			continue
+		}
+		files = append(files, File{Path: path, Graph: g})
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Path < files[j].Path
+	})
+
+	return files, nil
+}
+
+// Description returns metadata.description, or an empty string.
+func (g *Graph) Description() string {
+	description, _ := g.Metadata["description"].(string)
+	return description
+}
+
+func (g *Graph) Node(id string) *client.Node {
+	for i := range g.Nodes {
+		if g.Nodes[i].ID == id {
+			return &g.Nodes[i]
+		}
+	}
+	return nil
+}
+
+func (g *Graph) Edge(id string) *client.Edge {
+	for i := range g.Edges {
+		if g.Edges[i].ID == id {
+			return &g.Edges[i]
+		}
+	}
+	return nil
+}
+
+// NodeLabel returns the label of the node with the given ID, falling back to
+// the ID itself when the node is missing or unlabelled.
+func (g *Graph) NodeLabel(id string) string {
+	if n := g.Node(id); n != nil && n.Label != "" {
+		return n.Label
+	}
+	return id
+}
+
+// EdgeLabel returns the edge label, or "source -> target" when it has none.
+func (g *Graph) EdgeLabel(e client.Edge) string {
+	if e.Label != nil && *e.Label != "" {
+		return *e.Label
+	}
+	arrow := " -- "
+	if e.Directed {
+		arrow = " -> "
+	}
+	return g.NodeLabel(e.Source) + arrow + g.NodeLabel(e.Target)
+}
+
+// Open loads the graph with the given title, or the graph checked out with
+// 'tribal checkout' when title is empty.
+func Open(title string) (*File, error) {
+	if _, err := os.Stat(config.ConfigDir); os.IsNotExist(err) {
+		return nil, fmt.Errorf("not a tribal repository. Run 'tribal init' first")
+	}
+
+	path := PathFor(title)
+	if title == "" {
+		cfg, err := config.Load()
+		if err != nil {
+			return nil, err
+		}
+		if cfg.CurrentGraphFile == "" {
+			return nil, fmt.Errorf("no current graph checked out. Use 'tribal checkout -g\"<title>\"' first")
+		}
+		path = cfg.CurrentGraphFile
+	}
+
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil, fmt.Errorf("graph file does not exist: %s", path)
+	}
+
+	g, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &File{Path: path, Graph: g}, nil
+}
+
+// Checkout makes the graph with the given title the current graph,
+// creating an empty graph file for it if none exists. It reports whether
+// the graph was created.
+func Checkout(title, author string) (*File, bool, error) {
+	if _, err := os.Stat(config.ConfigDir); os.IsNotExist(err) {
+		return nil, false, fmt.Errorf("not a tribal repository. Run 'tribal init' first")
+	}
+
+	path := PathFor(title)
+	created := false
+	var g *Graph
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		if err := os.MkdirAll(Dir(), 0755); err != nil {
+			return nil, false, fmt.Errorf("failed to create graphs directory: %w", err)
+		}
+		g = &Graph{Title: title, Metadata: map[string]interface{}{
+			"created": time.Now().Format(time.RFC3339),
+			"author":  author,
+		}}
+		if err := g.Save(path); err != nil {
+			return nil, false, err
+		}
+		created = true
+	} else {
+		if g, err = Load(path); err != nil {
+			return nil, false, err
+		}
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, false, err
+	}
+	cfg.CurrentGraph = g.Title
+	cfg.CurrentGraphFile = path
+	if err := cfg.Save(); err != nil {
+		return nil, false, fmt.Errorf("failed to update config: %w", err)
+	}
+
+	return &File{Path: path, Graph: g}, created, nil
+}
+
+// NewNodeID returns an unused node ID of the form n<number>.
+func (g *Graph) NewNodeID() string {
+	for i := len(g.Nodes) + 1; ; i++ {
+		id := fmt.Sprintf("n%d", i)
+		if g.Node(id) == nil {
+			return id
+		}
+	}
+}
+
+// NewEdgeID returns an unused edge ID of the form e<number>.
+func (g *Graph) NewEdgeID() string {
+	for i := len(g.Edges) + 1; ; i++ {
+		id := fmt.Sprintf("e%d", i)
+		if g.Edge(id) == nil {
+			return id
+		}
+	}
+}
+
+// FindByLabel returns the first node whose label matches case-insensitively.
+func (g *Graph) FindByLabel(label string) *client.Node {
+	for i := range g.Nodes {
+		if strings.EqualFold(g.Nodes[i].Label, label) {
+			return &g.Nodes[i]
+		}
+	}
+	return nil
+}
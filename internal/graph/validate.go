@@ -0,0 +1,43 @@
+package graph
+
+import "fmt"
+
+// Validate checks that a graph is internally consistent: it has a title,
+// node and edge IDs are present and unique, and every edge joins two nodes
+// of the graph.
+func (g *Graph) Validate() []error {
+	var problems []error
+	if g.Title == "" {
+		problems = append(problems, fmt.Errorf("graph has no title"))
+	}
+
+	nodes := make(map[string]bool)
+	for i, n := range g.Nodes {
+		switch {
+		case n.ID == "":
+			problems = append(problems, fmt.Errorf("node %d (%q) has no ID", i+1, n.Label))
+		case nodes[n.ID]:
+			problems = append(problems, fmt.Errorf("node ID %s is used more than once", n.ID))
+		}
+		nodes[n.ID] = true
+	}
+
+	edges := make(map[string]bool)
+	for i, e := range g.Edges {
+		switch {
+		case e.ID == "":
+			problems = append(problems, fmt.Errorf("edge %d has no ID", i+1))
+		case edges[e.ID]:
+			problems = append(problems, fmt.Errorf("edge ID %s is used more than once", e.ID))
+		}
+		edges[e.ID] = true
+
+		if !nodes[e.Source] {
+			problems = append(problems, fmt.Errorf("edge %s starts at unknown node %q", e.ID, e.Source))
+		}
+		if !nodes[e.Target] {
+			problems = append(problems, fmt.Errorf("edge %s ends at unknown node %q", e.ID, e.Target))
+		}
+	}
+	return problems
+}
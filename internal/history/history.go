@@ -0,0 +1,44 @@
+// Package history records graph commits. Every way of committing, from
+// 'tribal commit', the git post-commit hook, the TUI or the MCP server, goes
+// through Commit so they all keep the same side effects.
+package history
+
+import (
+	"github.com/tribal/tribal-cli/internal/config"
+	"github.com/tribal/tribal-cli/internal/export"
+	"github.com/tribal/tribal-cli/internal/graph"
+)
+
+// Result describes a commit and the docs regenerated after it.
+type Result struct {
+	Commit *graph.Commit
+	Path   string
+	// DocsDir is the markdown_export_dir set with 'tribal export --all
+	// --on-commit', or "" when docs are not exported on commit.
+	DocsDir string
+	Docs    []string
+	// DocsErr reports docs that could not be regenerated. The commit
+	// itself is kept.
+	DocsErr error
+}
+
+// Commit writes g as a new commit and regenerates the markdown docs.
+func Commit(g *graph.Graph, message, author string) (*Result, error) {
+	c, path, err := graph.CommitGraph(g, message, author)
+	if err != nil {
+		return nil, err
+	}
+	r := &Result{Commit: c, Path: path}
+
+	cfg, err := config.Load()
+	if err != nil || cfg.MarkdownExportDir == "" {
+		return r, nil
+	}
+	r.DocsDir = cfg.MarkdownExportDir
+	files, err := graph.LoadAll()
+	if err == nil {
+		r.Docs, err = export.WriteAll("markdown", files, r.DocsDir)
+	}
+	r.DocsErr = err
+	return r, nil
+}
@@ -0,0 +1,417 @@
+package importer
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/tribal/tribal-cli/internal/client"
+	"github.com/tribal/tribal-cli/internal/graph"
+)
+
+// pointsPerInch converts Graphviz sizes, given in inches, to points.
+const pointsPerInch = 72
+
+// Graphviz's default node size, used when only one dimension is given.
+const (
+	defaultDOTWidth  = 0.75
+	defaultDOTHeight = 0.5
+)
+
+type dotToken struct {
+	text   string
+	quoted bool
+}
+
+type dotParser struct {
+	tokens   []dotToken
+	pos      int
+	directed bool
+	b        *builder
+	// depth counts the subgraphs being parsed; only top-level labels
+	// title the graph.
+	depth int
+}
+
+// ParseDOT reads a Graphviz DOT file. Subgraphs are flattened. Node labels,
+// pos, width, height and tooltip attributes are imported, as are edge labels,
+// tooltips and dir=none/back.
+func ParseDOT(data []byte) (*graph.Graph, error) {
+	tokens, err := lexDOT(string(data))
+	if err != nil {
+		return nil, err
+	}
+
+	p := &dotParser{tokens: tokens, b: newBuilder()}
+	if err := p.parseGraph(); err != nil {
+		return nil, err
+	}
+
+	return p.b.g, nil
+}
+
+func (p *dotParser) peek() string {
+	if p.pos >= len(p.tokens) {
+		return ""
+	}
+	return p.tokens[p.pos].text
+}
+
+func (p *dotParser) peekKeyword(word string) bool {
+	return p.pos < len(p.tokens) && !p.tokens[p.pos].quoted && strings.EqualFold(p.tokens[p.pos].text, word)
+}
+
+func (p *dotParser) next() (dotToken, error) {
+	if p.pos >= len(p.tokens) {
+		return dotToken{}, fmt.Errorf("unexpected end of file")
+	}
+	t := p.tokens[p.pos]
+	p.pos++
+	return t, nil
+}
+
+func (p *dotParser) expect(text string) error {
+	t, err := p.next()
+	if err != nil {
+		return err
+	}
+	if t.quoted || t.text != text {
+		return fmt.Errorf("expected %q, found %q", text, t.text)
+	}
+	return nil
+}
+
+func (p *dotParser) parseGraph() error {
+	if p.peekKeyword("strict") {
+		p.pos++
+	}
+
+	switch {
+	case p.peekKeyword("digraph"):
+		p.directed = true
+	case p.peekKeyword("graph"):
+	default:
+		return fmt.Errorf("expected 'graph' or 'digraph', found %q", p.peek())
+	}
+	p.pos++
+
+	if p.peek() != "{" {
+		t, err := p.next()
+		if err != nil {
+			return err
+		}
+		p.b.g.Title = t.text
+	}
+
+	if err := p.expect("{"); err != nil {
+		return err
+	}
+	if _, err := p.parseStatements(); err != nil {
+		return err
+	}
+	return p.expect("}")
+}
+
+// parseStatements parses statements up to a closing brace, returning the IDs
+// of every node they mention so that subgraphs can be used as edge endpoints.
+func (p *dotParser) parseStatements() ([]string, error) {
+	var mentioned []string
+	for p.peek() != "}" && p.peek() != "" {
+		ids, err := p.parseStatement()
+		if err != nil {
+			return nil, err
+		}
+		mentioned = append(mentioned, ids...)
+		if p.peek() == ";" || p.peek() == "," {
+			p.pos++
+		}
+	}
+	return mentioned, nil
+}
+
+func (p *dotParser) parseStatement() ([]string, error) {
+	switch {
+	case p.peekKeyword("graph"):
+		p.pos++
+		attrs, err := p.parseAttrs()
+		if err != nil {
+			return nil, err
+		}
+		if l, ok := attrs["label"]; ok && p.depth == 0 {
+			p.b.g.Title = l
+		}
+		return nil, nil
+	case p.peekKeyword("node"), p.peekKeyword("edge"):
+		p.pos++
+		_, err := p.parseAttrs()
+		return nil, err
+	}
+
+	if p.pos+1 < len(p.tokens) && p.tokens[p.pos+1].text == "=" && !p.tokens[p.pos+1].quoted {
+		key, _ := p.next()
+		p.pos++
+		value, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		if key.text == "label" && p.depth == 0 {
+			p.b.g.Title = value.text
+		}
+		return nil, nil
+	}
+
+	left, err := p.parseEndpoint()
+	if err != nil {
+		return nil, err
+	}
+	mentioned := append([]string(nil), left...)
+
+	type hop struct{ from, to []string }
+	var hops []hop
+	for p.peek() == "->" || p.peek() == "--" {
+		p.pos++
+		right, err := p.parseEndpoint()
+		if err != nil {
+			return nil, err
+		}
+		hops = append(hops, hop{left, right})
+		mentioned = append(mentioned, right...)
+		left = right
+	}
+
+	attrs, err := p.parseAttrs()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(hops) == 0 {
+		for _, id := range mentioned {
+			applyDOTNodeAttrs(p.b.node(id), attrs)
+		}
+		return mentioned, nil
+	}
+
+	for _, h := range hops {
+		for _, from := range h.from {
+			for _, to := range h.to {
+				p.addEdge(from, to, attrs)
+			}
+		}
+	}
+
+	return mentioned, nil
+}
+
+// parseEndpoint parses a node ID (ignoring any port) or a subgraph.
+func (p *dotParser) parseEndpoint() ([]string, error) {
+	if p.peekKeyword("subgraph") || p.peek() == "{" {
+		if p.peekKeyword("subgraph") {
+			p.pos++
+			if p.peek() != "{" {
+				p.pos++
+			}
+		}
+		if err := p.expect("{"); err != nil {
+			return nil, err
+		}
+		p.depth++
+		ids, err := p.parseStatements()
+		p.depth--
+		if err != nil {
+			return nil, err
+		}
+		return ids, p.expect("}")
+	}
+
+	t, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	if !t.quoted && strings.ContainsAny(t.text, "{}[];,=") {
+		return nil, fmt.Errorf("expected a node ID, found %q", t.text)
+	}
+
+	for p.peek() == ":" {
+		p.pos += 2
+	}
+
+	p.b.node(t.text)
+	return []string{t.text}, nil
+}
+
+func (p *dotParser) parseAttrs() (map[string]string, error) {
+	attrs := make(map[string]string)
+	for p.peek() == "[" {
+		p.pos++
+		for p.peek() != "]" {
+			key, err := p.next()
+			if err != nil {
+				return nil, err
+			}
+			value := "true"
+			if p.peek() == "=" {
+				p.pos++
+				v, err := p.next()
+				if err != nil {
+					return nil, err
+				}
+				value = v.text
+			}
+			attrs[strings.ToLower(key.text)] = value
+			if p.peek() == "," || p.peek() == ";" {
+				p.pos++
+			}
+		}
+		p.pos++
+	}
+	return attrs, nil
+}
+
+func (p *dotParser) addEdge(from, to string, attrs map[string]string) {
+	directed := p.directed
+	switch attrs["dir"] {
+	case "none":
+		directed = false
+	case "back":
+		from, to = to, from
+	case "forward", "both":
+		directed = true
+	}
+
+	e := p.b.edge(from, to, directed)
+	e.Label = stringPtr(attrs["label"])
+	e.Markup = stringPtr(attrs["tooltip"])
+}
+
+func applyDOTNodeAttrs(n *client.Node, attrs map[string]string) {
+	if l, ok := attrs["label"]; ok && l != `\N` {
+		n.Label = l
+	}
+	if tooltip, ok := attrs["tooltip"]; ok {
+		n.Markup = stringPtr(tooltip)
+	}
+	if pos, ok := attrs["pos"]; ok {
+		parts := strings.Split(strings.TrimSuffix(pos, "!"), ",")
+		if len(parts) >= 2 {
+			x, errX := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+			y, errY := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+			if errX == nil && errY == nil {
+				// Graphviz's y axis points up; graph positions grow downwards.
+				y = -y
+				if y == 0 {
+					y = 0 // avoid storing -0
+				}
+				n.Position = client.Position{X: x, Y: y}
+			}
+		}
+	}
+
+	width, hasWidth := parseInches(attrs["width"])
+	height, hasHeight := parseInches(attrs["height"])
+	if hasWidth || hasHeight {
+		if !hasWidth {
+			width = defaultDOTWidth * pointsPerInch
+		}
+		if !hasHeight {
+			height = defaultDOTHeight * pointsPerInch
+		}
+		n.Size = &client.Size{Width: width, Height: height}
+	}
+}
+
+func parseInches(s string) (float64, bool) {
+	if s == "" {
+		return 0, false
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, false
+	}
+	return v * pointsPerInch, true
+}
+
+func lexDOT(input string) ([]dotToken, error) {
+	var tokens []dotToken
+	runes := []rune(input)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '/' && i+1 < len(runes) && runes[i+1] == '/',
+			r == '#' && (i == 0 || runes[i-1] == '\n'):
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+		case r == '/' && i+1 < len(runes) && runes[i+1] == '*':
+			i += 2
+			for i+1 < len(runes) && !(runes[i] == '*' && runes[i+1] == '/') {
+				i++
+			}
+			if i+1 >= len(runes) {
+				return nil, fmt.Errorf("unterminated comment")
+			}
+			i += 2
+		case r == '"':
+			var b strings.Builder
+			i++
+			for ; i < len(runes) && runes[i] != '"'; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+					switch runes[i] {
+					case 'n', 'l', 'r':
+						b.WriteRune('\n')
+					case '"', '\\':
+						b.WriteRune(runes[i])
+					default:
+						b.WriteRune('\\')
+						b.WriteRune(runes[i])
+					}
+					continue
+				}
+				b.WriteRune(runes[i])
+			}
+			if i >= len(runes) {
+				return nil, fmt.Errorf("unterminated string")
+			}
+			i++
+			tokens = append(tokens, dotToken{text: b.String(), quoted: true})
+		case r == '<':
+			depth, start := 0, i
+			for ; i < len(runes); i++ {
+				if runes[i] == '<' {
+					depth++
+				} else if runes[i] == '>' {
+					depth--
+					if depth == 0 {
+						break
+					}
+				}
+			}
+			if i >= len(runes) {
+				return nil, fmt.Errorf("unterminated HTML string")
+			}
+			tokens = append(tokens, dotToken{text: string(runes[start+1 : i]), quoted: true})
+			i++
+		case r == '-' && i+1 < len(runes) && (runes[i+1] == '>' || runes[i+1] == '-'):
+			tokens = append(tokens, dotToken{text: string(runes[i : i+2])})
+			i += 2
+		case strings.ContainsRune("{}[];,=:", r):
+			tokens = append(tokens, dotToken{text: string(r)})
+			i++
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '.' || r == '-':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_' || runes[i] == '.' ||
+				(runes[i] == '-' && i == start)) {
+				i++
+			}
+			tokens = append(tokens, dotToken{text: string(runes[start:i])})
+		default:
+			return nil, fmt.Errorf("unexpected character %q", r)
+		}
+	}
+
+	return tokens, nil
+}
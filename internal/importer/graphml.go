@@ -0,0 +1,207 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/tribal/tribal-cli/internal/client"
+	"github.com/tribal/tribal-cli/internal/graph"
+)
+
+type graphMLDocument struct {
+	Keys   []graphMLKey   `xml:"key"`
+	Graphs []graphMLGraph `xml:"graph"`
+}
+
+type graphMLKey struct {
+	ID   string `xml:"id,attr"`
+	For  string `xml:"for,attr"`
+	Name string `xml:"attr.name,attr"`
+	// yEd stores node graphics under a key with yfiles.type instead of a name.
+	YFilesType string `xml:"yfiles.type,attr"`
+}
+
+type graphMLGraph struct {
+	ID          string        `xml:"id,attr"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Data        []graphMLData `xml:"data"`
+	Nodes       []graphMLNode `xml:"node"`
+	Edges       []graphMLEdge `xml:"edge"`
+}
+
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+	// Nested graphs are flattened into the parent.
+	Graphs []graphMLGraph `xml:"graph"`
+}
+
+type graphMLEdge struct {
+	ID       string        `xml:"id,attr"`
+	Source   string        `xml:"source,attr"`
+	Target   string        `xml:"target,attr"`
+	Directed string        `xml:"directed,attr"`
+	Data     []graphMLData `xml:"data"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+	Inner []byte `xml:",innerxml"`
+}
+
+// ParseGraphML reads a GraphML file. Data keys named label or name,
+// description or markup, x, y, width and height are mapped onto the node and
+// edge model, as is yEd's ShapeNode geometry and label.
+func ParseGraphML(data []byte) (*graph.Graph, error) {
+	var doc graphMLDocument
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Graphs) == 0 {
+		return nil, fmt.Errorf("no <graph> element found")
+	}
+
+	keys := make(map[string]graphMLKey)
+	for _, k := range doc.Keys {
+		k.Name = strings.ToLower(k.Name)
+		keys[k.ID] = k
+	}
+
+	b := newBuilder()
+	root := doc.Graphs[0]
+	for _, d := range root.Data {
+		if name := keys[d.Key].Name; name == "label" || name == "name" || name == "title" {
+			b.g.Title = strings.TrimSpace(d.Value)
+		}
+	}
+
+	addGraphML(b, root, keys)
+	return b.g, nil
+}
+
+func addGraphML(b *builder, g graphMLGraph, keys map[string]graphMLKey) {
+	directedDefault := g.EdgeDefault != "undirected"
+
+	for _, gn := range g.Nodes {
+		n := b.node(gn.ID)
+		var x, y, width, height float64
+		var hasPosition, hasSize bool
+
+		for _, d := range gn.Data {
+			k := keys[d.Key]
+			value := strings.TrimSpace(d.Value)
+
+			switch {
+			case k.YFilesType == "nodegraphics":
+				geometry, text := parseYEdShape(d.Inner)
+				if geometry != nil {
+					x, y, hasPosition = geometry.X, geometry.Y, true
+					width, height, hasSize = geometry.Width, geometry.Height, true
+				}
+				if text != "" {
+					n.Label = text
+				}
+			case k.Name == "label" || k.Name == "name":
+				n.Label = value
+			case k.Name == "description" || k.Name == "markup":
+				n.Markup = stringPtr(value)
+			case k.Name == "x":
+				x, hasPosition = parseFloat(value), true
+			case k.Name == "y":
+				y, hasPosition = parseFloat(value), true
+			case k.Name == "width":
+				width, hasSize = parseFloat(value), true
+			case k.Name == "height":
+				height, hasSize = parseFloat(value), true
+			}
+		}
+
+		if hasPosition {
+			n.Position = client.Position{X: x, Y: y}
+		}
+		if hasSize {
+			n.Size = &client.Size{Width: width, Height: height}
+		}
+
+		for _, nested := range gn.Graphs {
+			addGraphML(b, nested, keys)
+		}
+	}
+
+	for _, ge := range g.Edges {
+		directed := directedDefault
+		if ge.Directed != "" {
+			directed = ge.Directed == "true"
+		}
+
+		e := b.edge(ge.Source, ge.Target, directed)
+		if ge.ID != "" {
+			e.ID = ge.ID
+		}
+
+		for _, d := range ge.Data {
+			k := keys[d.Key]
+			value := strings.TrimSpace(d.Value)
+			switch {
+			case k.YFilesType == "edgegraphics":
+				if _, text := parseYEdShape(d.Inner); text != "" {
+					e.Label = stringPtr(text)
+				}
+			case k.Name == "label" || k.Name == "name":
+				e.Label = stringPtr(value)
+			case k.Name == "description" || k.Name == "markup":
+				e.Markup = stringPtr(value)
+			}
+		}
+	}
+}
+
+type yEdGeometry struct {
+	X      float64 `xml:"x,attr"`
+	Y      float64 `xml:"y,attr"`
+	Width  float64 `xml:"width,attr"`
+	Height float64 `xml:"height,attr"`
+}
+
+// parseYEdShape extracts the Geometry and first NodeLabel or EdgeLabel from
+// yEd graphics data.
+func parseYEdShape(inner []byte) (*yEdGeometry, string) {
+	dec := xml.NewDecoder(bytes.NewReader(inner))
+	var geometry *yEdGeometry
+	var text string
+
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			break
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		switch start.Name.Local {
+		case "Geometry":
+			var g yEdGeometry
+			if dec.DecodeElement(&g, &start) == nil {
+				geometry = &g
+			}
+		case "NodeLabel", "EdgeLabel":
+			var label string
+			if dec.DecodeElement(&label, &start) == nil && text == "" {
+				text = strings.TrimSpace(label)
+			}
+		}
+	}
+
+	return geometry, text
+}
+
+func parseFloat(s string) float64 {
+	v, _ := strconv.ParseFloat(s, 64)
+	return v
+}
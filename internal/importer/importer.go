@@ -0,0 +1,187 @@
+package importer
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/tribal/tribal-cli/internal/client"
+	"github.com/tribal/tribal-cli/internal/graph"
+)
+
+// Parser reads a diagram file into a graph.
+type Parser func(data []byte) (*graph.Graph, error)
+
+var parsers = map[string]Parser{
+	"dot":     ParseDOT,
+	"mermaid": ParseMermaid,
+	"graphml": ParseGraphML,
+}
+
+var extensions = map[string]string{
+	".dot":     "dot",
+	".gv":      "dot",
+	".mmd":     "mermaid",
+	".mermaid": "mermaid",
+	".graphml": "graphml",
+}
+
+// Formats lists the names accepted by Parse, sorted.
+func Formats() []string {
+	names := make([]string, 0, len(parsers))
+	for name := range parsers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// DetectFormat guesses the format of a file from its extension.
+func DetectFormat(path string) (string, bool) {
+	format, ok := extensions[strings.ToLower(filepath.Ext(path))]
+	return format, ok
+}
+
+func Parse(format string, data []byte) (*graph.Graph, error) {
+	parse, ok := parsers[strings.ToLower(format)]
+	if !ok {
+		return nil, fmt.Errorf("unknown format %q. Supported formats: %s", format, strings.Join(Formats(), ", "))
+	}
+
+	g, err := parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", format, err)
+	}
+	if g.Metadata == nil {
+		g.Metadata = make(map[string]interface{})
+	}
+
+	return g, nil
+}
+
+// MergeStats counts what Merge changed.
+type MergeStats struct {
+	NodesAdded   int
+	NodesMatched int
+	EdgesAdded   int
+	EdgesSkipped int
+}
+
+// Merge adds the nodes and edges of src to dst. Nodes are matched by
+// case-insensitive label; matched nodes keep their ID, markup and position,
+// gaining only the markup, position and size they were missing. Edges that
+// already exist between the same nodes with the same label and direction are
+// skipped.
+func Merge(dst, src *graph.Graph) MergeStats {
+	var stats MergeStats
+	ids := make(map[string]string)
+
+	for _, n := range src.Nodes {
+		originalID := n.ID
+		if existing := dst.FindByLabel(n.Label); existing != nil {
+			if existing.Markup == nil {
+				existing.Markup = n.Markup
+			}
+			if existing.Position == (client.Position{}) {
+				existing.Position = n.Position
+			}
+			if existing.Size == nil {
+				existing.Size = n.Size
+			}
+			ids[originalID] = existing.ID
+			stats.NodesMatched++
+			continue
+		}
+
+		if dst.Node(n.ID) != nil {
+			n.ID = dst.NewNodeID()
+		}
+		ids[originalID] = n.ID
+		dst.Nodes = append(dst.Nodes, n)
+		stats.NodesAdded++
+	}
+
+	for _, e := range src.Edges {
+		e.Source, e.Target = ids[e.Source], ids[e.Target]
+		if hasEdge(dst, e) {
+			stats.EdgesSkipped++
+			continue
+		}
+		if dst.Edge(e.ID) != nil {
+			e.ID = dst.NewEdgeID()
+		}
+		dst.Edges = append(dst.Edges, e)
+		stats.EdgesAdded++
+	}
+
+	return stats
+}
+
+func hasEdge(g *graph.Graph, e client.Edge) bool {
+	for _, existing := range g.Edges {
+		if existing.Directed != e.Directed || !strings.EqualFold(deref(existing.Label), deref(e.Label)) {
+			continue
+		}
+		if existing.Source == e.Source && existing.Target == e.Target {
+			return true
+		}
+		if !e.Directed && existing.Source == e.Target && existing.Target == e.Source {
+			return true
+		}
+	}
+	return false
+}
+
+// builder accumulates nodes and edges while a file is parsed, creating nodes
+// the first time they are referenced.
+type builder struct {
+	g     *graph.Graph
+	index map[string]int
+}
+
+func newBuilder() *builder {
+	return &builder{
+		g: &graph.Graph{
+			Nodes:    []client.Node{},
+			Edges:    []client.Edge{},
+			Metadata: make(map[string]interface{}),
+		},
+		index: make(map[string]int),
+	}
+}
+
+func (b *builder) node(id string) *client.Node {
+	if i, ok := b.index[id]; ok {
+		return &b.g.Nodes[i]
+	}
+	b.index[id] = len(b.g.Nodes)
+	b.g.Nodes = append(b.g.Nodes, client.Node{ID: id, Label: id})
+	return &b.g.Nodes[len(b.g.Nodes)-1]
+}
+
+func (b *builder) edge(source, target string, directed bool) *client.Edge {
+	b.node(source)
+	b.node(target)
+	b.g.Edges = append(b.g.Edges, client.Edge{
+		ID:       fmt.Sprintf("e%d", len(b.g.Edges)+1),
+		Source:   source,
+		Target:   target,
+		Directed: directed,
+	})
+	return &b.g.Edges[len(b.g.Edges)-1]
+}
+
+func stringPtr(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
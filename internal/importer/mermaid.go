@@ -0,0 +1,190 @@
+package importer
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/tribal/tribal-cli/internal/graph"
+)
+
+var (
+	mermaidID = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.]*`)
+	// A link with text between its halves, e.g. A -- calls --> B.
+	mermaidTextLink = regexp.MustCompile(`^(<)?(--|==|-\.)\s+(.+?)\s+(-{2,}|={2,}|\.-+)([>xo])?`)
+	// A plain link with an optional |label|, e.g. A -->|calls| B.
+	mermaidLink = regexp.MustCompile(`^(<)?(-{2,}|={2,}|-\.+-)([>xo])?(\|([^|]*)\|)?`)
+	// Statements that do not describe nodes or edges.
+	mermaidIgnored = regexp.MustCompile(`^(style|classDef|class|click|linkStyle|subgraph|end|direction)\b`)
+)
+
+// ParseMermaid reads a Mermaid flowchart. Node shapes, styling and subgraphs
+// are ignored; labels and arrow direction are kept. Mermaid diagrams carry no
+// positions.
+func ParseMermaid(data []byte) (*graph.Graph, error) {
+	b := newBuilder()
+	lines := strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+
+	// Optional front matter with a title.
+	if len(lines) > 0 && strings.TrimSpace(lines[0]) == "---" {
+		for i := 1; i < len(lines); i++ {
+			line := strings.TrimSpace(lines[i])
+			if line == "---" {
+				lines = lines[i+1:]
+				break
+			}
+			if strings.HasPrefix(line, "title:") {
+				b.g.Title = yamlScalar(strings.TrimSpace(strings.TrimPrefix(line, "title:")))
+			}
+		}
+	}
+
+	header := false
+	for n, raw := range lines {
+		for _, stmt := range strings.Split(raw, ";") {
+			line := strings.TrimSpace(stmt)
+			if line == "" || strings.HasPrefix(line, "%%") || mermaidIgnored.MatchString(line) {
+				continue
+			}
+
+			if !header {
+				if !strings.HasPrefix(line, "flowchart") && !strings.HasPrefix(line, "graph") {
+					return nil, fmt.Errorf("line %d: only flowchart diagrams are supported", n+1)
+				}
+				header = true
+				continue
+			}
+
+			if err := parseMermaidStatement(b, line); err != nil {
+				return nil, fmt.Errorf("line %d: %w", n+1, err)
+			}
+		}
+	}
+
+	if !header {
+		return nil, fmt.Errorf("no flowchart found")
+	}
+
+	return b.g, nil
+}
+
+// parseMermaidStatement parses a chain such as A[Label] -->|x| B & C --- D.
+func parseMermaidStatement(b *builder, line string) error {
+	left, rest, err := parseMermaidNodes(b, line)
+	if err != nil {
+		return err
+	}
+
+	for rest != "" {
+		var label string
+		var directed bool
+
+		if m := mermaidTextLink.FindStringSubmatch(rest); m != nil {
+			label, directed = m[3], m[5] != "" && m[1] == ""
+			rest = rest[len(m[0]):]
+		} else if m := mermaidLink.FindStringSubmatch(rest); m != nil {
+			label, directed = m[5], m[3] != "" && m[1] == ""
+			rest = rest[len(m[0]):]
+		} else {
+			return fmt.Errorf("unexpected %q", rest)
+		}
+
+		var right []string
+		right, rest, err = parseMermaidNodes(b, strings.TrimSpace(rest))
+		if err != nil {
+			return err
+		}
+
+		for _, from := range left {
+			for _, to := range right {
+				e := b.edge(from, to, directed)
+				e.Label = stringPtr(strings.Trim(strings.TrimSpace(label), `"`))
+			}
+		}
+		left = right
+	}
+
+	return nil
+}
+
+// parseMermaidNodes parses one or more nodes joined by &, returning their IDs
+// and the unparsed remainder of the line.
+func parseMermaidNodes(b *builder, s string) ([]string, string, error) {
+	var ids []string
+	for {
+		id := mermaidID.FindString(s)
+		if id == "" {
+			return nil, "", fmt.Errorf("expected a node ID at %q", s)
+		}
+		s = s[len(id):]
+		n := b.node(id)
+
+		label, rest, ok := parseMermaidShape(s)
+		if ok {
+			n.Label = label
+			s = rest
+		}
+		if strings.HasPrefix(s, ":::") {
+			s = strings.TrimLeft(s[3:], "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789_-")
+		}
+
+		ids = append(ids, id)
+		s = strings.TrimSpace(s)
+		if !strings.HasPrefix(s, "&") {
+			return ids, s, nil
+		}
+		s = strings.TrimSpace(s[1:])
+	}
+}
+
+// parseMermaidShape reads a node's shape and label, e.g. [Label], (Label),
+// {Label}, ((Label)), [(Label)] or >Label].
+func parseMermaidShape(s string) (string, string, bool) {
+	if s == "" || !strings.ContainsRune("[({>", rune(s[0])) {
+		return "", s, false
+	}
+
+	i := 1
+	for i < len(s) && strings.ContainsRune("[({/\\", rune(s[i])) {
+		i++
+	}
+	body := s[i:]
+
+	var label string
+	if strings.HasPrefix(body, `"`) {
+		end := strings.Index(body[1:], `"`)
+		if end < 0 {
+			return "", s, false
+		}
+		label = body[1 : end+1]
+		body = body[end+2:]
+	} else {
+		end := strings.IndexAny(body, "])}/\\")
+		if end < 0 {
+			return "", s, false
+		}
+		label = body[:end]
+		body = body[end:]
+	}
+
+	body = strings.TrimLeft(body, "])}/\\")
+	label = strings.ReplaceAll(label, "#quot;", `"`)
+	label = strings.ReplaceAll(label, "<br>", "\n")
+
+	return strings.TrimSpace(label), body, true
+}
+
+// yamlScalar returns the value of a single-line YAML scalar, removing the
+// quotes around double- and single-quoted strings.
+func yamlScalar(s string) string {
+	switch {
+	case len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"':
+		if v, err := strconv.Unquote(s); err == nil {
+			return v
+		}
+	case len(s) >= 2 && s[0] == '\'' && s[len(s)-1] == '\'':
+		return strings.ReplaceAll(s[1:len(s)-1], "''", "'")
+	}
+	return s
+}
@@ -0,0 +1,128 @@
+package layout
+
+import (
+	"math"
+
+	"github.com/tribal/tribal-cli/internal/graph"
+)
+
+const (
+	forceIterations = 300
+	// gravity pulls every node towards the origin, or towards the pinned
+	// nodes when there are any, so that disconnected components stay close
+	// to the rest of the graph.
+	gravity = 0.5
+)
+
+// Force positions nodes with the Fruchterman-Reingold force-directed
+// algorithm: every pair of nodes repels, connected nodes attract, a weak
+// gravity keeps components together, and the maximum movement per step cools
+// over time. Nodes start on a circle so the
+// result is deterministic. Pinned nodes repel and attract others but do not
+// move; the circle and the gravity are centred on them.
+func Force(g *graph.Graph, opts Options) {
+	n := len(g.Nodes)
+	if n == 0 {
+		return
+	}
+
+	index := make(map[string]int)
+	xs, ys := make([]float64, n), make([]float64, n)
+	k := float64(DefaultWidth + horizontalGap)
+	radius := k * math.Sqrt(float64(n)) / 2
+
+	var cx, cy float64
+	pinned := 0
+	for i, node := range g.Nodes {
+		index[node.ID] = i
+		if opts.Pinned[node.ID] {
+			xs[i], ys[i] = center(node)
+			cx, cy = cx+xs[i], cy+ys[i]
+			pinned++
+		}
+	}
+	if pinned > 0 {
+		cx, cy = cx/float64(pinned), cy/float64(pinned)
+	}
+
+	for i, node := range g.Nodes {
+		if opts.Pinned[node.ID] {
+			continue
+		}
+		angle := 2 * math.Pi * float64(i) / float64(n)
+		xs[i], ys[i] = cx+radius*math.Cos(angle), cy+radius*math.Sin(angle)
+	}
+
+	temperature := radius
+	for iter := 0; iter < forceIterations; iter++ {
+		dx, dy := make([]float64, n), make([]float64, n)
+
+		for i := 0; i < n; i++ {
+			for j := i + 1; j < n; j++ {
+				ddx, ddy, dist := delta(xs, ys, i, j)
+				force := k * k / dist
+				dx[i] += ddx / dist * force
+				dy[i] += ddy / dist * force
+				dx[j] -= ddx / dist * force
+				dy[j] -= ddy / dist * force
+			}
+		}
+
+		for _, e := range g.Edges {
+			i, okI := index[e.Source]
+			j, okJ := index[e.Target]
+			if !okI || !okJ || i == j {
+				continue
+			}
+			ddx, ddy, dist := delta(xs, ys, i, j)
+			force := dist * dist / k
+			dx[i] -= ddx / dist * force
+			dy[i] -= ddy / dist * force
+			dx[j] += ddx / dist * force
+			dy[j] += ddy / dist * force
+		}
+
+		for i, node := range g.Nodes {
+			if opts.Pinned[node.ID] {
+				continue
+			}
+			dx[i] -= (xs[i] - cx) * gravity
+			dy[i] -= (ys[i] - cy) * gravity
+
+			length := math.Hypot(dx[i], dy[i])
+			if length == 0 {
+				continue
+			}
+			step := math.Min(length, temperature)
+			xs[i] += dx[i] / length * step
+			ys[i] += dy[i] / length * step
+		}
+
+		temperature *= 0.97
+		if temperature < 1 {
+			temperature = 1
+		}
+	}
+
+	for i := range g.Nodes {
+		node := &g.Nodes[i]
+		if opts.Pinned[node.ID] {
+			continue
+		}
+		node.Position.X = math.Round(xs[i] - node.Size.Width/2)
+		node.Position.Y = math.Round(ys[i] - node.Size.Height/2)
+	}
+
+	normalize(g, opts)
+}
+
+// delta returns the vector from node j to node i and its length, nudging
+// coincident nodes apart.
+func delta(xs, ys []float64, i, j int) (float64, float64, float64) {
+	dx, dy := xs[i]-xs[j], ys[i]-ys[j]
+	dist := math.Hypot(dx, dy)
+	if dist < 0.01 {
+		dx, dy, dist = 0.01*float64(i-j), 0.01, 0.01*math.Hypot(float64(i-j), 1)
+	}
+	return dx, dy, dist
+}
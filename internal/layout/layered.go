@@ -0,0 +1,312 @@
+package layout
+
+import (
+	"math"
+	"sort"
+
+	"github.com/tribal/tribal-cli/internal/client"
+	"github.com/tribal/tribal-cli/internal/graph"
+)
+
+const (
+	orderingSweeps = 8
+	dummyWidth     = 20
+)
+
+// vertex is a node of the layered graph: either a real node or a dummy node
+// inserted where an edge spans several layers.
+type vertex struct {
+	node  int // index into g.Nodes, or -1 for dummies
+	edge  int // for dummies, index into g.Edges
+	layer int
+	order float64
+	up    []int
+	down  []int
+}
+
+// Slot is one position in a layer: a node, or a dummy where an edge passes
+// through the layer on its way to a lower one.
+type Slot struct {
+	Node int // index into g.Nodes, or -1 for dummies
+	Edge int // for dummies, index into g.Edges
+}
+
+// Layered positions nodes top to bottom following the Sugiyama method:
+// cycles are broken by reversing back edges, nodes are assigned to layers by
+// longest path, long edges are split with dummy nodes, and the order within
+// each layer is refined by repeated barycenter sweeps to reduce crossings.
+// Undirected edges are treated as pointing from source to target. Pinned
+// nodes keep their positions.
+func Layered(g *graph.Graph, opts Options) {
+	if len(g.Nodes) == 0 {
+		return
+	}
+
+	vertices, rows := layer(g)
+	assignCoordinates(g, vertices, rows, opts)
+	normalize(g, opts)
+}
+
+// Layers runs the layering and ordering stages of Layered and returns the
+// layers top to bottom, each ordered left to right. Every edge between two
+// distinct nodes joins adjacent layers, passing through one dummy slot in
+// each layer it crosses. Edges reversed to break cycles point upwards.
+func Layers(g *graph.Graph) [][]Slot {
+	if len(g.Nodes) == 0 {
+		return nil
+	}
+
+	vertices, rows := layer(g)
+	layers := make([][]Slot, len(rows))
+	for l, row := range rows {
+		for _, v := range row {
+			layers[l] = append(layers[l], Slot{Node: vertices[v].node, Edge: vertices[v].edge})
+		}
+	}
+	return layers
+}
+
+func layer(g *graph.Graph) ([]vertex, [][]int) {
+	n := len(g.Nodes)
+	index := make(map[string]int)
+	for i, node := range g.Nodes {
+		index[node.ID] = i
+	}
+
+	succ := make([][]int, n)
+	for _, e := range g.Edges {
+		s, okS := index[e.Source]
+		t, okT := index[e.Target]
+		if okS && okT && s != t {
+			succ[s] = append(succ[s], t)
+		}
+	}
+	layers := longestPathLayers(breakCycles(succ))
+
+	vertices := make([]vertex, n)
+	for i := range g.Nodes {
+		vertices[i] = vertex{node: i, edge: -1, layer: layers[i], order: float64(i)}
+	}
+	for i, e := range g.Edges {
+		s, okS := index[e.Source]
+		t, okT := index[e.Target]
+		if !okS || !okT || s == t {
+			continue
+		}
+		// Edges reversed to break a cycle run from the lower layer up.
+		if layers[s] > layers[t] {
+			s, t = t, s
+		}
+		prev := s
+		for l := layers[s] + 1; l < layers[t]; l++ {
+			vertices = append(vertices, vertex{node: -1, edge: i, layer: l, order: float64(len(vertices))})
+			dummy := len(vertices) - 1
+			vertices[prev].down = append(vertices[prev].down, dummy)
+			vertices[dummy].up = append(vertices[dummy].up, prev)
+			prev = dummy
+		}
+		vertices[prev].down = append(vertices[prev].down, t)
+		vertices[t].up = append(vertices[t].up, prev)
+	}
+
+	return vertices, orderLayers(vertices)
+}
+
+// breakCycles reverses the edges that close a cycle during a depth-first
+// search, leaving an acyclic graph.
+func breakCycles(succ [][]int) [][]int {
+	const (
+		unvisited = iota
+		active
+		done
+	)
+	state := make([]int, len(succ))
+	result := make([][]int, len(succ))
+
+	var visit func(v int)
+	visit = func(v int) {
+		state[v] = active
+		for _, t := range succ[v] {
+			switch state[t] {
+			case active:
+				result[t] = append(result[t], v)
+			case unvisited:
+				result[v] = append(result[v], t)
+				visit(t)
+			default:
+				result[v] = append(result[v], t)
+			}
+		}
+		state[v] = done
+	}
+
+	for v := range succ {
+		if state[v] == unvisited {
+			visit(v)
+		}
+	}
+	return result
+}
+
+// longestPathLayers places every node one layer below its deepest
+// predecessor, with sources in layer 0.
+func longestPathLayers(succ [][]int) []int {
+	inDegree := make([]int, len(succ))
+	for _, targets := range succ {
+		for _, t := range targets {
+			inDegree[t]++
+		}
+	}
+
+	var queue []int
+	for v, d := range inDegree {
+		if d == 0 {
+			queue = append(queue, v)
+		}
+	}
+
+	layers := make([]int, len(succ))
+	for len(queue) > 0 {
+		v := queue[0]
+		queue = queue[1:]
+		for _, t := range succ[v] {
+			if layers[v]+1 > layers[t] {
+				layers[t] = layers[v] + 1
+			}
+			inDegree[t]--
+			if inDegree[t] == 0 {
+				queue = append(queue, t)
+			}
+		}
+	}
+	return layers
+}
+
+// orderLayers groups vertices into rows by layer and reorders each row by the
+// average position of its neighbours in the adjacent row, sweeping down and
+// up alternately.
+func orderLayers(vertices []vertex) [][]int {
+	depth := 0
+	for _, v := range vertices {
+		if v.layer+1 > depth {
+			depth = v.layer + 1
+		}
+	}
+
+	rows := make([][]int, depth)
+	for i, v := range vertices {
+		rows[v.layer] = append(rows[v.layer], i)
+	}
+
+	sortRow := func(row []int, neighbours func(v vertex) []int) {
+		bary := make(map[int]float64)
+		for _, i := range row {
+			adj := neighbours(vertices[i])
+			if len(adj) == 0 {
+				bary[i] = vertices[i].order
+				continue
+			}
+			sum := 0.0
+			for _, a := range adj {
+				sum += vertices[a].order
+			}
+			bary[i] = sum / float64(len(adj))
+		}
+		sort.SliceStable(row, func(a, b int) bool {
+			return bary[row[a]] < bary[row[b]]
+		})
+		for pos, i := range row {
+			vertices[i].order = float64(pos)
+		}
+	}
+
+	for _, row := range rows {
+		for pos, i := range row {
+			vertices[i].order = float64(pos)
+		}
+	}
+
+	for sweep := 0; sweep < orderingSweeps; sweep++ {
+		if sweep%2 == 0 {
+			for l := 1; l < depth; l++ {
+				sortRow(rows[l], func(v vertex) []int { return v.up })
+			}
+		} else {
+			for l := depth - 2; l >= 0; l-- {
+				sortRow(rows[l], func(v vertex) []int { return v.down })
+			}
+		}
+	}
+
+	return rows
+}
+
+// assignCoordinates lays each row out left to right, centred on the widest
+// row, with rows stacked top to bottom. With pinned nodes the layout is
+// moved next to them.
+func assignCoordinates(g *graph.Graph, vertices []vertex, rows [][]int, opts Options) {
+	width := func(v vertex) float64 {
+		if v.node < 0 {
+			return dummyWidth
+		}
+		return g.Nodes[v.node].Size.Width
+	}
+
+	rowWidths := make([]float64, len(rows))
+	maxWidth := 0.0
+	for l, row := range rows {
+		for i, v := range row {
+			if i > 0 {
+				rowWidths[l] += horizontalGap
+			}
+			rowWidths[l] += width(vertices[v])
+		}
+		maxWidth = math.Max(maxWidth, rowWidths[l])
+	}
+
+	// Every node gets a position here, pinned or not, so that the rows can
+	// be lined up with the pinned nodes afterwards.
+	positions := make([]client.Position, len(g.Nodes))
+	y := 0.0
+	for l, row := range rows {
+		x := (maxWidth - rowWidths[l]) / 2
+		rowHeight := 0.0
+		for _, v := range row {
+			vx := vertices[v]
+			if vx.node >= 0 {
+				positions[vx.node] = client.Position{X: x, Y: y}
+				rowHeight = math.Max(rowHeight, g.Nodes[vx.node].Size.Height)
+			}
+			x += width(vx) + horizontalGap
+		}
+		if rowHeight == 0 {
+			rowHeight = DefaultHeight
+		}
+		y += rowHeight + verticalGap
+	}
+
+	// Shift the layout so the computed bounding box of the pinned nodes
+	// starts where theirs actually does.
+	var offsetX, offsetY float64
+	if len(opts.Pinned) > 0 {
+		wantX, wantY := math.Inf(1), math.Inf(1)
+		gotX, gotY := math.Inf(1), math.Inf(1)
+		for i, node := range g.Nodes {
+			if opts.Pinned[node.ID] {
+				wantX, wantY = math.Min(wantX, node.Position.X), math.Min(wantY, node.Position.Y)
+				gotX, gotY = math.Min(gotX, positions[i].X), math.Min(gotY, positions[i].Y)
+			}
+		}
+		if !math.IsInf(wantX, 1) {
+			offsetX, offsetY = wantX-gotX, wantY-gotY
+		}
+	}
+
+	for i := range g.Nodes {
+		node := &g.Nodes[i]
+		if !opts.Pinned[node.ID] {
+			node.Position.X = math.Round(positions[i].X + offsetX)
+			node.Position.Y = math.Round(positions[i].Y + offsetY)
+		}
+	}
+}
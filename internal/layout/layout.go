@@ -0,0 +1,154 @@
+package layout
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/tribal/tribal-cli/internal/client"
+	"github.com/tribal/tribal-cli/internal/graph"
+)
+
+// Default node size given to nodes that have none.
+const (
+	DefaultWidth  = 140
+	DefaultHeight = 44
+)
+
+// Spacing between neighbouring nodes.
+const (
+	horizontalGap = 60
+	verticalGap   = 80
+)
+
+// margin offsets normalized layouts from the origin, which marks a node as
+// having no position (see Positioned).
+const margin = 20
+
+type Options struct {
+	// Pinned holds the IDs of nodes whose positions must not change.
+	Pinned map[string]bool
+}
+
+// Algorithm positions the nodes of a graph in place.
+type Algorithm func(g *graph.Graph, opts Options)
+
+var algorithms = map[string]Algorithm{
+	"force":   Force,
+	"layered": Layered,
+}
+
+// Algorithms lists the names accepted by Apply, sorted.
+func Algorithms() []string {
+	names := make([]string, 0, len(algorithms))
+	for name := range algorithms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Apply gives every node without a size the default size, then positions
+// nodes with the named algorithm. Positions are the top-left corner of each
+// node.
+func Apply(g *graph.Graph, algorithm string, opts Options) error {
+	run, ok := algorithms[strings.ToLower(algorithm)]
+	if !ok {
+		return fmt.Errorf("unknown layout algorithm %q. Supported algorithms: %s", algorithm, strings.Join(Algorithms(), ", "))
+	}
+
+	for i := range g.Nodes {
+		if g.Nodes[i].Size == nil {
+			g.Nodes[i].Size = &client.Size{Width: DefaultWidth, Height: DefaultHeight}
+		}
+	}
+	if opts.Pinned == nil {
+		opts.Pinned = make(map[string]bool)
+	}
+
+	run(g, opts)
+	return nil
+}
+
+// Positioned returns the IDs of nodes that have a position other than the
+// origin, for use as Options.Pinned.
+func Positioned(g *graph.Graph) map[string]bool {
+	ids := make(map[string]bool)
+	for _, n := range g.Nodes {
+		if n.Position != (client.Position{}) {
+			ids[n.ID] = true
+		}
+	}
+	return ids
+}
+
+// center returns the center of a node given its top-left position and size.
+func center(n client.Node) (float64, float64) {
+	return n.Position.X + n.Size.Width/2, n.Position.Y + n.Size.Height/2
+}
+
+// normalize shifts unpinned layouts so the top-left node sits at (margin,
+// margin). Layouts with pinned nodes are left in place so pinned positions
+// hold, and the other nodes are moved down clear of them and of the origin.
+func normalize(g *graph.Graph, opts Options) {
+	if len(g.Nodes) == 0 {
+		return
+	}
+	if len(opts.Pinned) > 0 {
+		separate(g, opts)
+		return
+	}
+
+	minX, minY := g.Nodes[0].Position.X, g.Nodes[0].Position.Y
+	for _, n := range g.Nodes {
+		if n.Position.X < minX {
+			minX = n.Position.X
+		}
+		if n.Position.Y < minY {
+			minY = n.Position.Y
+		}
+	}
+	for i := range g.Nodes {
+		g.Nodes[i].Position.X += margin - minX
+		g.Nodes[i].Position.Y += margin - minY
+	}
+}
+
+// separate moves each unpinned node down until it is at least margin away
+// from the pinned nodes and the unpinned nodes placed before it, and off the
+// origin.
+func separate(g *graph.Graph, opts Options) {
+	var placed []int
+	for i, n := range g.Nodes {
+		if opts.Pinned[n.ID] {
+			placed = append(placed, i)
+		}
+	}
+
+	for i := range g.Nodes {
+		n := &g.Nodes[i]
+		if opts.Pinned[n.ID] {
+			continue
+		}
+		for moved := true; moved; {
+			moved = false
+			if n.Position == (client.Position{}) {
+				n.Position.Y = margin
+				moved = true
+			}
+			for _, j := range placed {
+				if other := g.Nodes[j]; overlaps(*n, other) {
+					n.Position.Y = other.Position.Y + other.Size.Height + margin
+					moved = true
+				}
+			}
+		}
+		placed = append(placed, i)
+	}
+}
+
+// overlaps reports whether two nodes are closer than margin to each other.
+func overlaps(a, b client.Node) bool {
+	return a.Position.X < b.Position.X+b.Size.Width+margin && b.Position.X < a.Position.X+a.Size.Width+margin &&
+		a.Position.Y < b.Position.Y+b.Size.Height+margin && b.Position.Y < a.Position.Y+a.Size.Height+margin
+}
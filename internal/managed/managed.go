@@ -0,0 +1,94 @@
+// Package managed maintains blocks of generated text inside files that may
+// also hold content written by hand. Each block sits between a begin and an
+// end marker line; only the text between the markers is ever rewritten.
+package managed
+
+import "strings"
+
+// Markers delimit a managed block. Both are matched as whole lines.
+type Markers struct {
+	Begin string
+	End   string
+}
+
+// ShellMarkers suit shell scripts such as git hooks.
+var ShellMarkers = Markers{Begin: "# >>> tribal >>>", End: "# <<< tribal <<<"}
+
+// MarkdownMarkers suit markdown files, where they render invisibly.
+var MarkdownMarkers = Markers{Begin: "<!-- tribal:begin -->", End: "<!-- tribal:end -->"}
+
+// Apply returns content with the managed block replaced by body. When the
+// content has no block yet, the block is appended, or prepended when
+// prepend is set. Text outside the markers is left untouched.
+func Apply(content, body string, m Markers, prepend bool) string {
+	block := m.Begin + "\n" + strings.TrimRight(body, "\n") + "\n" + m.End + "\n"
+
+	if start, end, ok := find(content, m); ok {
+		return content[:start] + block + content[end:]
+	}
+
+	switch {
+	case content == "":
+		return block
+	case prepend:
+		return block + "\n" + content
+	case strings.HasSuffix(content, "\n"):
+		return content + "\n" + block
+	default:
+		return content + "\n\n" + block
+	}
+}
+
+// Has reports whether content contains a managed block.
+func Has(content string, m Markers) bool {
+	_, _, ok := find(content, m)
+	return ok
+}
+
+// Body returns the text inside the managed block.
+func Body(content string, m Markers) (string, bool) {
+	start, end, ok := find(content, m)
+	if !ok {
+		return "", false
+	}
+	block := content[start:end]
+	block = strings.TrimPrefix(block, m.Begin+"\n")
+	block = strings.TrimSuffix(strings.TrimSuffix(block, "\n"), m.End)
+	return strings.TrimSuffix(block, "\n"), true
+}
+
+// find locates the block from the start of its begin line to just after
+// the newline ending its end line.
+func find(content string, m Markers) (int, int, bool) {
+	start := lineIndex(content, m.Begin, 0)
+	if start < 0 {
+		return 0, 0, false
+	}
+	endLine := lineIndex(content, m.End, start)
+	if endLine < 0 {
+		return 0, 0, false
+	}
+
+	end := endLine + len(m.End)
+	if end < len(content) && content[end] == '\n' {
+		end++
+	}
+	return start, end, true
+}
+
+// lineIndex finds line as a whole line of content at or after from.
+func lineIndex(content, line string, from int) int {
+	for i := from; i < len(content); {
+		j := strings.Index(content[i:], line)
+		if j < 0 {
+			return -1
+		}
+		pos := i + j
+		after := pos + len(line)
+		if (pos == 0 || content[pos-1] == '\n') && (after == len(content) || content[after] == '\n' || content[after] == '\r') {
+			return pos
+		}
+		i = after
+	}
+	return -1
+}
@@ -0,0 +1,115 @@
+package mcp
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+
+	"github.com/tribal/tribal-cli/internal/graph"
+)
+
+// Resource URIs name graphs by the stem of their file name, which is stable
+// and URI-safe: tribal://graphs/<stem> and tribal://graphs/<stem>/nodes/<id>.
+const uriPrefix = "tribal://graphs/"
+
+const mimeJSON = "application/json"
+
+type resource struct {
+	URI         string `json:"uri"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType"`
+}
+
+func graphURI(path string) string {
+	return uriPrefix + strings.TrimSuffix(filepath.Base(path), ".json")
+}
+
+func nodeURI(path, id string) string {
+	return graphURI(path) + "/nodes/" + id
+}
+
+func listResources() (interface{}, error) {
+	files, err := graph.LoadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	resources := []resource{}
+	for _, f := range files {
+		resources = append(resources, resource{
+			URI:         graphURI(f.Path),
+			Name:        f.Graph.Title,
+			Description: f.Graph.Description(),
+			MimeType:    mimeJSON,
+		})
+		for _, n := range f.Graph.Nodes {
+			resources = append(resources, resource{
+				URI:      nodeURI(f.Path, n.ID),
+				Name:     f.Graph.Title + " / " + n.Label,
+				MimeType: mimeJSON,
+			})
+		}
+	}
+	return map[string]interface{}{"resources": resources}, nil
+}
+
+func listResourceTemplates() interface{} {
+	return map[string]interface{}{
+		"resourceTemplates": []map[string]interface{}{
+			{
+				"uriTemplate": uriPrefix + "{graph}",
+				"name":        "Graph",
+				"description": "A graph with all of its nodes and edges. {graph} is the graph's file name without .json.",
+				"mimeType":    mimeJSON,
+			},
+			{
+				"uriTemplate": uriPrefix + "{graph}/nodes/{id}",
+				"name":        "Node",
+				"description": "A node with its markup, code anchors and connected nodes.",
+				"mimeType":    mimeJSON,
+			},
+		},
+	}
+}
+
+func readResource(params json.RawMessage) (interface{}, error) {
+	var p struct {
+		URI string `json:"uri"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, errorf(codeInvalidParams, "invalid resources/read params: %v", err)
+	}
+
+	rest := strings.TrimPrefix(p.URI, uriPrefix)
+	if rest == p.URI || rest == "" {
+		return nil, errorf(codeNotFound, "resource not found: %s", p.URI)
+	}
+	stem, nodeID := rest, ""
+	if i := strings.Index(rest, "/nodes/"); i >= 0 {
+		stem, nodeID = rest[:i], rest[i+len("/nodes/"):]
+	}
+	if strings.ContainsAny(stem, `/\`) || stem == "." || stem == ".." {
+		return nil, errorf(codeNotFound, "resource not found: %s", p.URI)
+	}
+
+	g, err := graph.Load(filepath.Join(graph.Dir(), stem+".json"))
+	if err != nil {
+		return nil, errorf(codeNotFound, "resource not found: %s", p.URI)
+	}
+
+	var v interface{} = g
+	if nodeID != "" {
+		n := g.Node(nodeID)
+		if n == nil {
+			return nil, errorf(codeNotFound, "resource not found: %s", p.URI)
+		}
+		v = detail(g, n)
+	}
+
+	return map[string]interface{}{
+		"contents": []map[string]interface{}{
+			{"uri": p.URI, "mimeType": mimeJSON, "text": string(indent(v))},
+		},
+	}, nil
+}
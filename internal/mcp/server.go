@@ -0,0 +1,218 @@
+// Package mcp implements a Model Context Protocol server over stdio, so
+// coding agents can search and edit graphs with structured JSON instead of
+// parsing command output. Messages are newline-delimited JSON-RPC 2.0.
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// protocolVersions are the MCP revisions the server speaks, newest first.
+var protocolVersions = []string{"2025-06-18", "2025-03-26", "2024-11-05"}
+
+// JSON-RPC and MCP error codes.
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+	codeInternalError  = -32603
+	codeNotFound       = -32002
+)
+
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string {
+	return e.Message
+}
+
+func errorf(code int, format string, args ...interface{}) *rpcError {
+	return &rpcError{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// Server answers MCP requests about the tribal repository in the current
+// directory.
+type Server struct {
+	version string
+	tools   []tool
+	log     io.Writer
+}
+
+// NewServer returns a server reporting the given version, writing
+// diagnostics to log. Nothing but protocol messages may go to stdout.
+func NewServer(version string, log io.Writer) *Server {
+	return &Server{version: version, tools: tools(), log: log}
+}
+
+// Serve reads requests from in and writes responses to out until in is
+// closed.
+func (s *Server) Serve(in io.Reader, out io.Writer) error {
+	reader := bufio.NewReader(in)
+	writer := bufio.NewWriter(out)
+
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			if reply := s.handleMessage(bytes.TrimSpace(line)); reply != nil {
+				writer.Write(reply)
+				writer.WriteByte('\n')
+				if err := writer.Flush(); err != nil {
+					return err
+				}
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// handleMessage processes a single request or a batch, returning the
+// encoded reply or nil when there is nothing to send.
+func (s *Server) handleMessage(data []byte) []byte {
+	if data[0] == '[' {
+		var batch []json.RawMessage
+		if err := json.Unmarshal(data, &batch); err != nil || len(batch) == 0 {
+			return encode(response{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: errorf(codeInvalidRequest, "invalid batch")})
+		}
+		var replies []response
+		for _, msg := range batch {
+			if r := s.handle(msg); r != nil {
+				replies = append(replies, *r)
+			}
+		}
+		if len(replies) == 0 {
+			return nil
+		}
+		return encode(replies)
+	}
+
+	if r := s.handle(data); r != nil {
+		return encode(*r)
+	}
+	return nil
+}
+
+func (s *Server) handle(data []byte) *response {
+	var req request
+	if err := json.Unmarshal(data, &req); err != nil {
+		return &response{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: errorf(codeParseError, "parse error: %v", err)}
+	}
+
+	notification := len(req.ID) == 0
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		if notification {
+			return nil
+		}
+		return &response{JSONRPC: "2.0", ID: req.ID, Error: errorf(codeInvalidRequest, "invalid request")}
+	}
+
+	result, err := s.dispatch(req)
+	if notification {
+		if err != nil {
+			fmt.Fprintf(s.log, "tribal mcp: %s: %v\n", req.Method, err)
+		}
+		return nil
+	}
+
+	resp := &response{JSONRPC: "2.0", ID: req.ID}
+	if err != nil {
+		rerr, ok := err.(*rpcError)
+		if !ok {
+			rerr = errorf(codeInternalError, "%v", err)
+		}
+		resp.Error = rerr
+	} else {
+		resp.Result = result
+	}
+	return resp
+}
+
+func (s *Server) dispatch(req request) (interface{}, error) {
+	switch req.Method {
+	case "initialize":
+		return s.initialize(req.Params)
+	case "ping":
+		return map[string]interface{}{}, nil
+	case "tools/list":
+		return s.listTools(), nil
+	case "tools/call":
+		return s.callTool(req.Params)
+	case "resources/list":
+		return listResources()
+	case "resources/templates/list":
+		return listResourceTemplates(), nil
+	case "resources/read":
+		return readResource(req.Params)
+	}
+
+	if len(req.Method) > len("notifications/") && req.Method[:len("notifications/")] == "notifications/" {
+		return nil, nil
+	}
+	return nil, errorf(codeMethodNotFound, "method not found: %s", req.Method)
+}
+
+func (s *Server) initialize(params json.RawMessage) (interface{}, error) {
+	var p struct {
+		ProtocolVersion string `json:"protocolVersion"`
+	}
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, errorf(codeInvalidParams, "invalid initialize params: %v", err)
+		}
+	}
+
+	version := protocolVersions[0]
+	for _, v := range protocolVersions {
+		if v == p.ProtocolVersion {
+			version = v
+		}
+	}
+
+	return map[string]interface{}{
+		"protocolVersion": version,
+		"capabilities": map[string]interface{}{
+			"tools":     map[string]interface{}{},
+			"resources": map[string]interface{}{},
+		},
+		"serverInfo": map[string]interface{}{
+			"name":    "tribal",
+			"version": s.version,
+		},
+		"instructions": "Tribal stores knowledge graphs of this codebase: nodes are components or concepts with markdown markup, edges are relationships. " +
+			"Search before starting a task, read the relevant nodes, and record what you learn by adding or updating nodes and edges. " +
+			"Graph arguments are graph titles and default to the current graph; node arguments accept an ID or a label.",
+	}, nil
+}
+
+func encode(v interface{}) []byte {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	enc.Encode(v)
+	return bytes.TrimRight(b.Bytes(), "\n")
+}
@@ -0,0 +1,691 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/tribal/tribal-cli/internal/client"
+	"github.com/tribal/tribal-cli/internal/graph"
+	"github.com/tribal/tribal-cli/internal/history"
+	"github.com/tribal/tribal-cli/internal/search"
+)
+
+// tool is an MCP tool: its advertised definition and the handler that runs
+// it with the raw call arguments.
+type tool struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	InputSchema map[string]interface{} `json:"inputSchema"`
+	handler     func(args json.RawMessage) (interface{}, error)
+}
+
+// schema builds an object schema from property definitions.
+func schema(required []string, properties map[string]interface{}) map[string]interface{} {
+	s := map[string]interface{}{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		s["required"] = required
+	}
+	return s
+}
+
+func str(description string) map[string]interface{} {
+	return map[string]interface{}{"type": "string", "description": description}
+}
+
+func boolean(description string) map[string]interface{} {
+	return map[string]interface{}{"type": "boolean", "description": description}
+}
+
+var graphProperty = str("Graph title. Defaults to the current graph.")
+
+func tools() []tool {
+	return []tool{
+		{
+			Name:        "list_graphs",
+			Description: "List the graphs in the repository with their node and edge counts.",
+			InputSchema: schema(nil, map[string]interface{}{}),
+			handler:     listGraphs,
+		},
+		{
+			Name:        "search",
+			Description: "Search graph titles and descriptions, node labels and markup, and edge labels and markup. Results are ranked by how many query terms they match.",
+			InputSchema: schema([]string{"query"}, map[string]interface{}{
+				"query": str("Words describing what to look for."),
+				"type":  map[string]interface{}{"type": "string", "enum": []string{"graph", "node", "edge"}, "description": "Only return results of this type."},
+				"graph": str("Only search the graph with this title."),
+			}),
+			handler: searchGraphs,
+		},
+		{
+			Name:        "checkout",
+			Description: "Make a graph the current graph, creating it if it does not exist.",
+			InputSchema: schema([]string{"title"}, map[string]interface{}{
+				"title": str("Graph title."),
+			}),
+			handler: checkout,
+		},
+		{
+			Name:        "get_graph",
+			Description: "Return a graph with all of its nodes and edges.",
+			InputSchema: schema(nil, map[string]interface{}{
+				"graph": graphProperty,
+			}),
+			handler: getGraph,
+		},
+		{
+			Name:        "get_node",
+			Description: "Return a node with its markup, code anchors and the nodes connected to it.",
+			InputSchema: schema([]string{"node"}, map[string]interface{}{
+				"node":  str("Node ID or label."),
+				"graph": graphProperty,
+			}),
+			handler: getNode,
+		},
+		{
+			Name:        "add_node",
+			Description: "Add a node to a graph.",
+			InputSchema: schema([]string{"label"}, map[string]interface{}{
+				"label":  str("Node label."),
+				"markup": str("Markdown describing the node."),
+				"graph":  graphProperty,
+			}),
+			handler: addNode,
+		},
+		{
+			Name:        "update_node",
+			Description: "Change a node's label or markup. Omitted fields are left as they are; an empty markup removes it.",
+			InputSchema: schema([]string{"node"}, map[string]interface{}{
+				"node":   str("Node ID or label."),
+				"label":  str("New label."),
+				"markup": str("New markdown markup."),
+				"graph":  graphProperty,
+			}),
+			handler: updateNode,
+		},
+		{
+			Name:        "delete_node",
+			Description: "Delete a node and the edges attached to it.",
+			InputSchema: schema([]string{"node"}, map[string]interface{}{
+				"node":  str("Node ID or label."),
+				"graph": graphProperty,
+			}),
+			handler: deleteNode,
+		},
+		{
+			Name:        "add_edge",
+			Description: "Connect two nodes.",
+			InputSchema: schema([]string{"source", "target"}, map[string]interface{}{
+				"source":   str("Source node ID or label."),
+				"target":   str("Target node ID or label."),
+				"label":    str("Relationship, e.g. 'calls' or 'depends on'."),
+				"markup":   str("Markdown describing the relationship."),
+				"directed": boolean("Whether the edge points from source to target. Defaults to true."),
+				"graph":    graphProperty,
+			}),
+			handler: addEdge,
+		},
+		{
+			Name:        "update_edge",
+			Description: "Change an edge's label, markup or direction. Omitted fields are left as they are; empty strings remove the label or markup.",
+			InputSchema: schema([]string{"edge"}, map[string]interface{}{
+				"edge":     str("Edge ID."),
+				"label":    str("New label."),
+				"markup":   str("New markdown markup."),
+				"directed": boolean("Whether the edge points from source to target."),
+				"graph":    graphProperty,
+			}),
+			handler: updateEdge,
+		},
+		{
+			Name:        "delete_edge",
+			Description: "Delete an edge.",
+			InputSchema: schema([]string{"edge"}, map[string]interface{}{
+				"edge":  str("Edge ID."),
+				"graph": graphProperty,
+			}),
+			handler: deleteEdge,
+		},
+		{
+			Name:        "diff",
+			Description: "List the nodes and edges added, removed or changed since the graph was last committed.",
+			InputSchema: schema(nil, map[string]interface{}{
+				"graph": graphProperty,
+			}),
+			handler: diff,
+		},
+		{
+			Name:        "commit",
+			Description: "Commit a snapshot of a graph with a message, like 'tribal add -A' followed by 'tribal commit'.",
+			InputSchema: schema([]string{"message"}, map[string]interface{}{
+				"message": str("Commit message."),
+				"author":  str("Commit author. Defaults to 'user'."),
+				"graph":   graphProperty,
+			}),
+			handler: commit,
+		},
+	}
+}
+
+func (s *Server) listTools() interface{} {
+	return map[string]interface{}{"tools": s.tools}
+}
+
+func (s *Server) callTool(params json.RawMessage) (interface{}, error) {
+	var p struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, errorf(codeInvalidParams, "invalid tools/call params: %v", err)
+	}
+
+	for _, t := range s.tools {
+		if t.Name != p.Name {
+			continue
+		}
+		args := p.Arguments
+		if len(args) == 0 || string(args) == "null" {
+			args = json.RawMessage("{}")
+		}
+
+		// Tool failures are reported in the result so the model can see
+		// them and recover, rather than as protocol errors.
+		result, err := t.handler(args)
+		if err != nil {
+			return map[string]interface{}{
+				"content": []interface{}{textContent(err.Error())},
+				"isError": true,
+			}, nil
+		}
+		structured, err := toObject(result)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{
+			"content":           []interface{}{textContent(string(indent(structured)))},
+			"structuredContent": structured,
+		}, nil
+	}
+	return nil, errorf(codeInvalidParams, "unknown tool: %s", p.Name)
+}
+
+func textContent(text string) map[string]interface{} {
+	return map[string]interface{}{"type": "text", "text": text}
+}
+
+// toObject round-trips v through JSON so structured content is always a
+// plain JSON object.
+func toObject(v interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var obj map[string]interface{}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+func indent(v interface{}) []byte {
+	data, _ := json.MarshalIndent(v, "", "  ")
+	return data
+}
+
+func decode(args json.RawMessage, v interface{}) error {
+	if err := json.Unmarshal(args, v); err != nil {
+		return fmt.Errorf("invalid arguments: %v", err)
+	}
+	return nil
+}
+
+// graphSummary describes a graph without its contents.
+type graphSummary struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	URI         string `json:"uri"`
+	Nodes       int    `json:"nodes"`
+	Edges       int    `json:"edges"`
+	Current     bool   `json:"current,omitempty"`
+}
+
+func summarize(f graph.File, current string) graphSummary {
+	return graphSummary{
+		Title:       f.Graph.Title,
+		Description: f.Graph.Description(),
+		URI:         graphURI(f.Path),
+		Nodes:       len(f.Graph.Nodes),
+		Edges:       len(f.Graph.Edges),
+		Current:     current != "" && filepath.Clean(f.Path) == filepath.Clean(current),
+	}
+}
+
+func currentGraphFile() string {
+	f, err := graph.Open("")
+	if err != nil {
+		return ""
+	}
+	return f.Path
+}
+
+func listGraphs(args json.RawMessage) (interface{}, error) {
+	files, err := graph.LoadAll()
+	if err != nil {
+		return nil, err
+	}
+	current := currentGraphFile()
+	graphs := []graphSummary{}
+	for _, f := range files {
+		graphs = append(graphs, summarize(f, current))
+	}
+	return map[string]interface{}{"graphs": graphs}, nil
+}
+
+type searchResult struct {
+	Kind    search.Kind `json:"kind"`
+	Graph   string      `json:"graph"`
+	ID      string      `json:"id,omitempty"`
+	Label   string      `json:"label"`
+	Score   int         `json:"score"`
+	Snippet string      `json:"snippet,omitempty"`
+}
+
+func searchGraphs(args json.RawMessage) (interface{}, error) {
+	var p struct {
+		Query string `json:"query"`
+		Type  string `json:"type"`
+		Graph string `json:"graph"`
+	}
+	if err := decode(args, &p); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(p.Query) == "" {
+		return nil, fmt.Errorf("query is required")
+	}
+	kind, ok := search.ParseKind(p.Type)
+	if !ok {
+		return nil, fmt.Errorf("invalid type %q. Use node, edge or graph", p.Type)
+	}
+
+	results, err := search.Local(p.Query, search.Options{Kind: kind, Graph: p.Graph})
+	if err != nil {
+		return nil, err
+	}
+	out := []searchResult{}
+	for _, r := range results {
+		out = append(out, searchResult{
+			Kind:    r.Kind,
+			Graph:   r.GraphTitle,
+			ID:      r.ID,
+			Label:   r.Label,
+			Score:   r.Score,
+			Snippet: r.Snippet.Text,
+		})
+	}
+	return map[string]interface{}{"results": out}, nil
+}
+
+func checkout(args json.RawMessage) (interface{}, error) {
+	var p struct {
+		Title string `json:"title"`
+	}
+	if err := decode(args, &p); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(p.Title) == "" {
+		return nil, fmt.Errorf("title is required")
+	}
+
+	f, created, err := graph.Checkout(p.Title, "user")
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"graph":   summarize(*f, f.Path),
+		"created": created,
+	}, nil
+}
+
+func getGraph(args json.RawMessage) (interface{}, error) {
+	var p struct {
+		Graph string `json:"graph"`
+	}
+	if err := decode(args, &p); err != nil {
+		return nil, err
+	}
+	f, err := graph.Open(p.Graph)
+	if err != nil {
+		return nil, err
+	}
+	return f.Graph, nil
+}
+
+// neighbor is a node connected to another, with the edge joining them.
+type neighbor struct {
+	Node      client.Node     `json:"node"`
+	Edge      client.Edge     `json:"edge"`
+	Direction graph.Direction `json:"direction"`
+}
+
+type nodeDetail struct {
+	Graph     string      `json:"graph"`
+	Node      client.Node `json:"node"`
+	Neighbors []neighbor  `json:"neighbors"`
+}
+
+func detail(g *graph.Graph, n *client.Node) nodeDetail {
+	d := nodeDetail{Graph: g.Title, Node: *n, Neighbors: []neighbor{}}
+	for _, nb := range g.Neighbors(n.ID) {
+		d.Neighbors = append(d.Neighbors, neighbor{Node: nb.Node, Edge: nb.Edge, Direction: nb.Direction})
+	}
+	return d
+}
+
+func getNode(args json.RawMessage) (interface{}, error) {
+	var p struct {
+		Node  string `json:"node"`
+		Graph string `json:"graph"`
+	}
+	if err := decode(args, &p); err != nil {
+		return nil, err
+	}
+	f, err := graph.Open(p.Graph)
+	if err != nil {
+		return nil, err
+	}
+	n, err := f.Graph.Resolve(p.Node)
+	if err != nil {
+		return nil, err
+	}
+	return detail(f.Graph, n), nil
+}
+
+// edit loads a graph, applies fn and saves the result.
+func edit(title string, fn func(g *graph.Graph) (interface{}, error)) (interface{}, error) {
+	f, err := graph.Open(title)
+	if err != nil {
+		return nil, err
+	}
+	result, err := fn(f.Graph)
+	if err != nil {
+		return nil, err
+	}
+	if err := f.Graph.Save(f.Path); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// optional returns nil for an empty string so cleared fields are omitted.
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+func addNode(args json.RawMessage) (interface{}, error) {
+	var p struct {
+		Label  string `json:"label"`
+		Markup string `json:"markup"`
+		Graph  string `json:"graph"`
+	}
+	if err := decode(args, &p); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(p.Label) == "" {
+		return nil, fmt.Errorf("label is required")
+	}
+
+	return edit(p.Graph, func(g *graph.Graph) (interface{}, error) {
+		if existing := g.FindByLabel(p.Label); existing != nil {
+			return nil, fmt.Errorf("node %q already exists with ID %s", existing.Label, existing.ID)
+		}
+		n := client.Node{ID: g.NewNodeID(), Label: p.Label, Markup: optional(p.Markup)}
+		g.Nodes = append(g.Nodes, n)
+		return map[string]interface{}{"graph": g.Title, "node": n}, nil
+	})
+}
+
+func updateNode(args json.RawMessage) (interface{}, error) {
+	var p struct {
+		Node   string  `json:"node"`
+		Label  *string `json:"label"`
+		Markup *string `json:"markup"`
+		Graph  string  `json:"graph"`
+	}
+	if err := decode(args, &p); err != nil {
+		return nil, err
+	}
+
+	return edit(p.Graph, func(g *graph.Graph) (interface{}, error) {
+		n, err := g.Resolve(p.Node)
+		if err != nil {
+			return nil, err
+		}
+		if p.Label != nil {
+			if strings.TrimSpace(*p.Label) == "" {
+				return nil, fmt.Errorf("label cannot be empty")
+			}
+			if other := g.FindByLabel(*p.Label); other != nil && other.ID != n.ID {
+				return nil, fmt.Errorf("node %q already exists with ID %s", other.Label, other.ID)
+			}
+			n.Label = *p.Label
+		}
+		if p.Markup != nil {
+			n.Markup = optional(*p.Markup)
+		}
+		return map[string]interface{}{"graph": g.Title, "node": *n}, nil
+	})
+}
+
+func deleteNode(args json.RawMessage) (interface{}, error) {
+	var p struct {
+		Node  string `json:"node"`
+		Graph string `json:"graph"`
+	}
+	if err := decode(args, &p); err != nil {
+		return nil, err
+	}
+
+	return edit(p.Graph, func(g *graph.Graph) (interface{}, error) {
+		n, err := g.Resolve(p.Node)
+		if err != nil {
+			return nil, err
+		}
+		deleted := *n
+
+		nodes := g.Nodes[:0]
+		for _, node := range g.Nodes {
+			if node.ID != deleted.ID {
+				nodes = append(nodes, node)
+			}
+		}
+		g.Nodes = nodes
+
+		removed := []client.Edge{}
+		var edges []client.Edge
+		for _, e := range g.Edges {
+			if e.Source == deleted.ID || e.Target == deleted.ID {
+				removed = append(removed, e)
+				continue
+			}
+			edges = append(edges, e)
+		}
+		g.Edges = edges
+
+		return map[string]interface{}{"graph": g.Title, "node": deleted, "edges_removed": removed}, nil
+	})
+}
+
+func addEdge(args json.RawMessage) (interface{}, error) {
+	var p struct {
+		Source   string `json:"source"`
+		Target   string `json:"target"`
+		Label    string `json:"label"`
+		Markup   string `json:"markup"`
+		Directed *bool  `json:"directed"`
+		Graph    string `json:"graph"`
+	}
+	if err := decode(args, &p); err != nil {
+		return nil, err
+	}
+
+	return edit(p.Graph, func(g *graph.Graph) (interface{}, error) {
+		source, err := g.Resolve(p.Source)
+		if err != nil {
+			return nil, err
+		}
+		target, err := g.Resolve(p.Target)
+		if err != nil {
+			return nil, err
+		}
+		e := client.Edge{
+			ID:       g.NewEdgeID(),
+			Source:   source.ID,
+			Target:   target.ID,
+			Directed: p.Directed == nil || *p.Directed,
+			Label:    optional(p.Label),
+			Markup:   optional(p.Markup),
+		}
+		g.Edges = append(g.Edges, e)
+		return map[string]interface{}{"graph": g.Title, "edge": e}, nil
+	})
+}
+
+func updateEdge(args json.RawMessage) (interface{}, error) {
+	var p struct {
+		Edge     string  `json:"edge"`
+		Label    *string `json:"label"`
+		Markup   *string `json:"markup"`
+		Directed *bool   `json:"directed"`
+		Graph    string  `json:"graph"`
+	}
+	if err := decode(args, &p); err != nil {
+		return nil, err
+	}
+
+	return edit(p.Graph, func(g *graph.Graph) (interface{}, error) {
+		e := g.Edge(p.Edge)
+		if e == nil {
+			return nil, fmt.Errorf("edge not found: %s", p.Edge)
+		}
+		if p.Label != nil {
+			e.Label = optional(*p.Label)
+		}
+		if p.Markup != nil {
+			e.Markup = optional(*p.Markup)
+		}
+		if p.Directed != nil {
+			e.Directed = *p.Directed
+		}
+		return map[string]interface{}{"graph": g.Title, "edge": *e}, nil
+	})
+}
+
+func deleteEdge(args json.RawMessage) (interface{}, error) {
+	var p struct {
+		Edge  string `json:"edge"`
+		Graph string `json:"graph"`
+	}
+	if err := decode(args, &p); err != nil {
+		return nil, err
+	}
+
+	return edit(p.Graph, func(g *graph.Graph) (interface{}, error) {
+		e := g.Edge(p.Edge)
+		if e == nil {
+			return nil, fmt.Errorf("edge not found: %s", p.Edge)
+		}
+		deleted := *e
+
+		edges := g.Edges[:0]
+		for _, edge := range g.Edges {
+			if edge.ID != deleted.ID {
+				edges = append(edges, edge)
+			}
+		}
+		g.Edges = edges
+		return map[string]interface{}{"graph": g.Title, "edge": deleted}, nil
+	})
+}
+
+func diff(args json.RawMessage) (interface{}, error) {
+	var p struct {
+		Graph string `json:"graph"`
+	}
+	if err := decode(args, &p); err != nil {
+		return nil, err
+	}
+	f, err := graph.Open(p.Graph)
+	if err != nil {
+		return nil, err
+	}
+	latest, err := graph.LatestCommit(f.Graph.Title)
+	if err != nil {
+		return nil, err
+	}
+
+	result := map[string]interface{}{"graph": f.Graph.Title}
+	var before *graph.Graph
+	if latest != nil {
+		before = latest.Graph
+		result["commit"] = latest.ID
+	}
+	changes := graph.Diff(before, f.Graph)
+	result["changes"] = changes
+	result["empty"] = changes.Empty()
+	return result, nil
+}
+
+func commit(args json.RawMessage) (interface{}, error) {
+	var p struct {
+		Message string `json:"message"`
+		Author  string `json:"author"`
+		Graph   string `json:"graph"`
+	}
+	if err := decode(args, &p); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(p.Message) == "" {
+		return nil, fmt.Errorf("message is required")
+	}
+	if p.Author == "" {
+		p.Author = "user"
+	}
+
+	f, err := graph.Open(p.Graph)
+	if err != nil {
+		return nil, err
+	}
+	if problems := f.Graph.Validate(); len(problems) > 0 {
+		var msgs []string
+		for _, problem := range problems {
+			msgs = append(msgs, problem.Error())
+		}
+		return nil, fmt.Errorf("graph %q is invalid:\n%s", f.Graph.Title, strings.Join(msgs, "\n"))
+	}
+
+	r, err := history.Commit(f.Graph, p.Message, p.Author)
+	if err != nil {
+		return nil, err
+	}
+	c := r.Commit
+	result := map[string]interface{}{
+		"id":        c.ID,
+		"graph":     f.Graph.Title,
+		"message":   c.Message,
+		"timestamp": c.Timestamp,
+	}
+	if sha := c.GitCommit(); sha != "" {
+		result["git_commit"] = sha
+	}
+	if r.DocsErr != nil {
+		result["docs_error"] = r.DocsErr.Error()
+	} else if r.DocsDir != "" {
+		result["docs_regenerated"] = len(r.Docs)
+	}
+	return result, nil
+}
@@ -0,0 +1,91 @@
+// Package plan describes file writes before making them, so commands such
+// as 'tribal init' can print what they would change with --dry-run and
+// skip files whose content is already up to date.
+package plan
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// Action is what applying a change does to its file.
+type Action string
+
+const (
+	Create    Action = "create"
+	Update    Action = "update"
+	Unchanged Action = "unchanged"
+	Skip      Action = "skip"
+)
+
+// Change is a planned write of Content to Path.
+type Change struct {
+	Path    string
+	Content []byte
+	Mode    os.FileMode
+	Action  Action
+	// Note explains the change, or why the file is skipped.
+	Note string
+}
+
+// File plans writing content to path, comparing it with what is there.
+func File(path string, content []byte, note string) (Change, error) {
+	c := Change{Path: path, Content: content, Mode: 0644, Note: note}
+
+	existing, err := ioutil.ReadFile(path)
+	switch {
+	case os.IsNotExist(err):
+		c.Action = Create
+	case err != nil:
+		return c, fmt.Errorf("failed to read %s: %w", path, err)
+	case bytes.Equal(existing, content):
+		c.Action = Unchanged
+	default:
+		c.Action = Update
+	}
+	return c, nil
+}
+
+// Skipped plans leaving path alone for the given reason.
+func Skipped(path, reason string) Change {
+	return Change{Path: path, Action: Skip, Note: reason}
+}
+
+// Apply writes the change, creating parent directories as needed. Skipped
+// and unchanged files are not touched.
+func (c Change) Apply() error {
+	if c.Action != Create && c.Action != Update {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(c.Path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", c.Path, err)
+	}
+	if err := ioutil.WriteFile(c.Path, c.Content, c.Mode); err != nil {
+		return fmt.Errorf("failed to write %s: %w", c.Path, err)
+	}
+	return nil
+}
+
+// Describe returns a one-line summary of the change, phrased as planned
+// ("Would create") or done ("Created").
+func (c Change) Describe(dryRun bool) string {
+	verbs := map[Action][2]string{
+		Create:    {"Would create", "Created"},
+		Update:    {"Would update", "Updated"},
+		Unchanged: {"Unchanged", "Unchanged"},
+		Skip:      {"Would skip", "Skipped"},
+	}
+	verb := verbs[c.Action][1]
+	if dryRun {
+		verb = verbs[c.Action][0]
+	}
+
+	line := fmt.Sprintf("%-12s %s", verb, c.Path)
+	if c.Note != "" {
+		line += " (" + c.Note + ")"
+	}
+	return line
+}
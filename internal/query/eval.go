@@ -0,0 +1,229 @@
+package query
+
+import (
+	"github.com/tribal/tribal-cli/internal/client"
+	"github.com/tribal/tribal-cli/internal/graph"
+)
+
+// Result holds the nodes matched by the final pattern of a query, along with
+// the subgraph of every node and edge on a path that led to them.
+type Result struct {
+	Nodes    []client.Node
+	Subgraph *graph.Graph
+}
+
+// hop records how a step reached a target node: the node it started from and
+// the edges and intermediate nodes along the way.
+type hop struct {
+	from  string
+	edges []int
+	nodes []string
+}
+
+type adjacency struct {
+	edge  int
+	other string
+}
+
+// Eval runs a query against a graph. Depth is measured as the length of the
+// shortest path between a step's start node and its target.
+func Eval(q *Query, g *graph.Graph) *Result {
+	out := make(map[string][]adjacency)
+	in := make(map[string][]adjacency)
+	for i, e := range g.Edges {
+		out[e.Source] = append(out[e.Source], adjacency{i, e.Target})
+		in[e.Target] = append(in[e.Target], adjacency{i, e.Source})
+	}
+
+	current := matchingNodes(g, q.Start, nil)
+	steps := make([]map[string][]hop, len(q.Steps))
+
+	for i, step := range q.Steps {
+		reached := make(map[string][]hop)
+		for _, from := range current {
+			for target, h := range traverse(g, step, from, out, in) {
+				reached[target] = append(reached[target], h)
+			}
+		}
+
+		steps[i] = reached
+		current = matchingNodes(g, step.Node, reached)
+	}
+
+	return buildResult(g, current, steps)
+}
+
+// traverse runs a breadth-first search from one node, returning every node
+// whose depth falls within the step's range along with the path to it.
+func traverse(g *graph.Graph, step Step, from string, out, in map[string][]adjacency) map[string]hop {
+	type visit struct {
+		parent string
+		edge   int
+		depth  int
+	}
+
+	visited := map[string]visit{from: {edge: -1}}
+	queue := []string{from}
+
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		depth := visited[id].depth
+		if step.Max != Unbounded && depth >= step.Max {
+			continue
+		}
+
+		var candidates []adjacency
+		switch step.Direction {
+		case Outgoing:
+			candidates = directed(g, out[id])
+		case Incoming:
+			candidates = directed(g, in[id])
+		case Any:
+			candidates = append(append(candidates, out[id]...), in[id]...)
+		}
+
+		for _, adj := range candidates {
+			if _, seen := visited[adj.other]; seen {
+				continue
+			}
+			if !matches(step.Edge, edgeFieldValues(g, g.Edges[adj.edge])) {
+				continue
+			}
+			visited[adj.other] = visit{parent: id, edge: adj.edge, depth: depth + 1}
+			queue = append(queue, adj.other)
+		}
+	}
+
+	reached := make(map[string]hop)
+	for id, v := range visited {
+		if v.depth < step.Min {
+			continue
+		}
+
+		h := hop{from: from}
+		for cur := id; cur != from; cur = visited[cur].parent {
+			h.edges = append(h.edges, visited[cur].edge)
+			h.nodes = append(h.nodes, cur)
+		}
+		reached[id] = h
+	}
+
+	return reached
+}
+
+func directed(g *graph.Graph, adj []adjacency) []adjacency {
+	var result []adjacency
+	for _, a := range adj {
+		if g.Edges[a.edge].Directed {
+			result = append(result, a)
+		}
+	}
+	return result
+}
+
+// matchingNodes returns the IDs of nodes matching pred in graph order. When
+// allowed is non-nil only nodes present in it are considered.
+func matchingNodes(g *graph.Graph, pred Predicate, allowed map[string][]hop) []string {
+	var ids []string
+	for _, n := range g.Nodes {
+		if allowed != nil {
+			if _, ok := allowed[n.ID]; !ok {
+				continue
+			}
+		}
+		if matches(pred, nodeFieldValues(n)) {
+			ids = append(ids, n.ID)
+		}
+	}
+	return ids
+}
+
+// buildResult walks the recorded hops backwards from the final nodes so the
+// subgraph only contains paths that produced a result.
+func buildResult(g *graph.Graph, final []string, steps []map[string][]hop) *Result {
+	nodeSet := make(map[string]bool)
+	edgeSet := make(map[int]bool)
+
+	live := make(map[string]bool)
+	for _, id := range final {
+		live[id] = true
+		nodeSet[id] = true
+	}
+
+	for i := len(steps) - 1; i >= 0; i-- {
+		prev := make(map[string]bool)
+		for id := range live {
+			for _, h := range steps[i][id] {
+				prev[h.from] = true
+				nodeSet[h.from] = true
+				for _, e := range h.edges {
+					edgeSet[e] = true
+				}
+				for _, n := range h.nodes {
+					nodeSet[n] = true
+				}
+			}
+		}
+		live = prev
+	}
+
+	result := &Result{
+		Subgraph: &graph.Graph{
+			Title:    g.Title,
+			Nodes:    []client.Node{},
+			Edges:    []client.Edge{},
+			Metadata: make(map[string]interface{}),
+		},
+	}
+
+	finalSet := make(map[string]bool)
+	for _, id := range final {
+		finalSet[id] = true
+	}
+
+	for _, n := range g.Nodes {
+		if finalSet[n.ID] {
+			result.Nodes = append(result.Nodes, n)
+		}
+		if nodeSet[n.ID] {
+			result.Subgraph.Nodes = append(result.Subgraph.Nodes, n)
+		}
+	}
+	for i, e := range g.Edges {
+		if edgeSet[i] {
+			result.Subgraph.Edges = append(result.Subgraph.Edges, e)
+		}
+	}
+
+	return result
+}
+
+func matches(pred Predicate, fields map[string]string) bool {
+	return pred == nil || pred.match(fields)
+}
+
+func nodeFieldValues(n client.Node) map[string]string {
+	return map[string]string{
+		"id":     n.ID,
+		"label":  n.Label,
+		"markup": deref(n.Markup),
+	}
+}
+
+func edgeFieldValues(g *graph.Graph, e client.Edge) map[string]string {
+	return map[string]string{
+		"id":     e.ID,
+		"label":  deref(e.Label),
+		"markup": deref(e.Markup),
+		"source": g.NodeLabel(e.Source),
+		"target": g.NodeLabel(e.Target),
+	}
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,137 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokLParen
+	tokRParen
+	tokLBrack
+	tokRBrack
+	tokDash
+	tokArrowRight
+	tokArrowLeft
+	tokStar
+	tokDotDot
+	tokNumber
+	tokString
+	tokIdent
+	tokOp
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func (t token) String() string {
+	if t.kind == tokEOF {
+		return "end of query"
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+func lex(input string) ([]token, error) {
+	var tokens []token
+	runes := []rune(input)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		start := i
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+			continue
+		case r == '(':
+			tokens = append(tokens, token{tokLParen, "(", start})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{tokRParen, ")", start})
+			i++
+		case r == '[':
+			tokens = append(tokens, token{tokLBrack, "[", start})
+			i++
+		case r == ']':
+			tokens = append(tokens, token{tokRBrack, "]", start})
+			i++
+		case r == '*':
+			tokens = append(tokens, token{tokStar, "*", start})
+			i++
+		case r == '-':
+			if i+1 < len(runes) && runes[i+1] == '>' {
+				tokens = append(tokens, token{tokArrowRight, "->", start})
+				i += 2
+			} else {
+				tokens = append(tokens, token{tokDash, "-", start})
+				i++
+			}
+		case r == '<':
+			if i+1 >= len(runes) || runes[i+1] != '-' {
+				return nil, fmt.Errorf("unexpected '<' at position %d", start)
+			}
+			tokens = append(tokens, token{tokArrowLeft, "<-", start})
+			i += 2
+		case r == '.':
+			if i+1 >= len(runes) || runes[i+1] != '.' {
+				return nil, fmt.Errorf("unexpected '.' at position %d", start)
+			}
+			tokens = append(tokens, token{tokDotDot, "..", start})
+			i += 2
+		case r == '=' || r == '!' || r == '~':
+			op := string(r)
+			if i+1 < len(runes) && (runes[i+1] == '=' || runes[i+1] == '~') && r != '~' {
+				op += string(runes[i+1])
+			}
+			switch op {
+			case "=", "!=", "~", "=~", "!~":
+			default:
+				return nil, fmt.Errorf("unknown operator %q at position %d", op, start)
+			}
+			tokens = append(tokens, token{tokOp, op, start})
+			i += len(op)
+		case r == '"' || r == '\'':
+			var b strings.Builder
+			i++
+			for {
+				if i >= len(runes) {
+					return nil, fmt.Errorf("unterminated string starting at position %d", start)
+				}
+				if runes[i] == '\\' && i+1 < len(runes) {
+					b.WriteRune(runes[i+1])
+					i += 2
+					continue
+				}
+				if runes[i] == r {
+					i++
+					break
+				}
+				b.WriteRune(runes[i])
+				i++
+			}
+			tokens = append(tokens, token{tokString, b.String(), start})
+		case unicode.IsDigit(r):
+			for i < len(runes) && unicode.IsDigit(runes[i]) {
+				i++
+			}
+			tokens = append(tokens, token{tokNumber, string(runes[start:i]), start})
+		case unicode.IsLetter(r) || r == '_':
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
+				i++
+			}
+			tokens = append(tokens, token{tokIdent, string(runes[start:i]), start})
+		default:
+			return nil, fmt.Errorf("unexpected %q at position %d", r, start)
+		}
+	}
+
+	tokens = append(tokens, token{tokEOF, "", len(runes)})
+	return tokens, nil
+}
@@ -0,0 +1,369 @@
+package query
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Direction controls which edges a step may traverse.
+type Direction int
+
+const (
+	// Outgoing follows directed edges from source to target.
+	Outgoing Direction = iota
+	// Incoming follows directed edges from target to source.
+	Incoming
+	// Any follows every edge in either direction.
+	Any
+)
+
+// Unbounded is the Max of a step with no upper depth limit.
+const Unbounded = -1
+
+// Query is a parsed path pattern: a start node pattern followed by zero or
+// more edge/node steps.
+type Query struct {
+	Start Predicate
+	Steps []Step
+}
+
+// Step matches nodes reachable from the previous step's nodes over edges
+// matching Edge, in Direction, at a shortest-path depth between Min and Max.
+type Step struct {
+	Edge      Predicate
+	Direction Direction
+	Min       int
+	Max       int
+	Node      Predicate
+}
+
+// Predicate tests a node or edge. A nil Predicate matches everything.
+type Predicate interface {
+	match(fields map[string]string) bool
+}
+
+type comparison struct {
+	field string
+	op    string
+	value string
+	re    *regexp.Regexp
+}
+
+func (c comparison) match(fields map[string]string) bool {
+	actual := fields[c.field]
+	switch c.op {
+	case "=":
+		return strings.EqualFold(actual, c.value)
+	case "!=":
+		return !strings.EqualFold(actual, c.value)
+	case "~":
+		return strings.Contains(strings.ToLower(actual), strings.ToLower(c.value))
+	case "=~":
+		return c.re.MatchString(actual)
+	case "!~":
+		return !c.re.MatchString(actual)
+	}
+	return false
+}
+
+type and struct{ left, right Predicate }
+
+func (a and) match(fields map[string]string) bool {
+	return a.left.match(fields) && a.right.match(fields)
+}
+
+type or struct{ left, right Predicate }
+
+func (o or) match(fields map[string]string) bool {
+	return o.left.match(fields) || o.right.match(fields)
+}
+
+type not struct{ inner Predicate }
+
+func (n not) match(fields map[string]string) bool {
+	return !n.inner.match(fields)
+}
+
+var (
+	nodeFields = map[string]bool{"id": true, "label": true, "markup": true}
+	edgeFields = map[string]bool{"id": true, "label": true, "markup": true, "source": true, "target": true}
+)
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+// Parse parses a query expression.
+func Parse(input string) (*Query, error) {
+	tokens, err := lex(input)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	q, err := p.parseQuery()
+	if err != nil {
+		return nil, err
+	}
+
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, fmt.Errorf("unexpected %s at position %d", t, t.pos)
+	}
+
+	return q, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) expect(kind tokenKind, what string) (token, error) {
+	t := p.next()
+	if t.kind != kind {
+		return t, fmt.Errorf("expected %s at position %d, found %s", what, t.pos, t)
+	}
+	return t, nil
+}
+
+func (p *parser) parseQuery() (*Query, error) {
+	start, err := p.parseNode()
+	if err != nil {
+		return nil, err
+	}
+
+	q := &Query{Start: start}
+	for {
+		switch p.peek().kind {
+		case tokDash, tokArrowLeft, tokArrowRight:
+		default:
+			return q, nil
+		}
+
+		step, err := p.parseEdge()
+		if err != nil {
+			return nil, err
+		}
+		step.Node, err = p.parseNode()
+		if err != nil {
+			return nil, err
+		}
+		q.Steps = append(q.Steps, step)
+	}
+}
+
+func (p *parser) parseNode() (Predicate, error) {
+	if _, err := p.expect(tokLParen, "'('"); err != nil {
+		return nil, err
+	}
+
+	if p.peek().kind == tokRParen {
+		p.next()
+		return nil, nil
+	}
+
+	pred, err := p.parseOr(nodeFields)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := p.expect(tokRParen, "')'"); err != nil {
+		return nil, err
+	}
+
+	return pred, nil
+}
+
+// parseEdge parses one of: ->, --, <-, -[...]->, -[...]-, <-[...]-
+func (p *parser) parseEdge() (Step, error) {
+	step := Step{Min: 1, Max: 1}
+
+	first := p.next()
+	switch first.kind {
+	case tokArrowRight:
+		step.Direction = Outgoing
+		return step, nil
+	case tokArrowLeft:
+		step.Direction = Incoming
+	case tokDash:
+		step.Direction = Any
+	}
+
+	if p.peek().kind == tokLBrack {
+		p.next()
+		if err := p.parseEdgeBody(&step); err != nil {
+			return step, err
+		}
+		if _, err := p.expect(tokRBrack, "']'"); err != nil {
+			return step, err
+		}
+	} else if first.kind == tokArrowLeft {
+		if p.peek().kind == tokDash {
+			p.next()
+		}
+		return step, nil
+	}
+
+	end := p.next()
+	switch {
+	case end.kind == tokArrowRight && first.kind == tokDash:
+		step.Direction = Outgoing
+	case end.kind == tokDash:
+	default:
+		return step, fmt.Errorf("expected '->' or '-' at position %d, found %s", end.pos, end)
+	}
+
+	return step, nil
+}
+
+func (p *parser) parseEdgeBody(step *Step) error {
+	if k := p.peek().kind; k != tokStar && k != tokRBrack {
+		pred, err := p.parseOr(edgeFields)
+		if err != nil {
+			return err
+		}
+		step.Edge = pred
+	}
+
+	if p.peek().kind != tokStar {
+		return nil
+	}
+	p.next()
+
+	// "*" alone means one or more hops; "*N" exactly N; "*N.." at least N;
+	// "*..M" up to M; "*N..M" between N and M.
+	step.Min, step.Max = 1, Unbounded
+	if p.peek().kind == tokNumber {
+		n, _ := strconv.Atoi(p.next().text)
+		step.Min, step.Max = n, n
+	}
+	if p.peek().kind == tokDotDot {
+		p.next()
+		step.Max = Unbounded
+		if p.peek().kind == tokNumber {
+			n, _ := strconv.Atoi(p.next().text)
+			step.Max = n
+		}
+	}
+
+	if step.Max != Unbounded && step.Max < step.Min {
+		return fmt.Errorf("invalid depth range %d..%d", step.Min, step.Max)
+	}
+
+	return nil
+}
+
+func (p *parser) parseOr(fields map[string]bool) (Predicate, error) {
+	left, err := p.parseAnd(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	for p.isKeyword("or") {
+		p.next()
+		right, err := p.parseAnd(fields)
+		if err != nil {
+			return nil, err
+		}
+		left = or{left, right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseAnd(fields map[string]bool) (Predicate, error) {
+	left, err := p.parseUnary(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	for p.isKeyword("and") {
+		p.next()
+		right, err := p.parseUnary(fields)
+		if err != nil {
+			return nil, err
+		}
+		left = and{left, right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseUnary(fields map[string]bool) (Predicate, error) {
+	t := p.peek()
+
+	switch {
+	case t.kind == tokIdent && strings.EqualFold(t.text, "not"):
+		p.next()
+		inner, err := p.parseUnary(fields)
+		if err != nil {
+			return nil, err
+		}
+		return not{inner}, nil
+	case t.kind == tokLParen:
+		p.next()
+		inner, err := p.parseOr(fields)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(tokRParen, "')'"); err != nil {
+			return nil, err
+		}
+		return inner, nil
+	case t.kind == tokString:
+		// A bare string is shorthand for label = "...".
+		p.next()
+		return comparison{field: "label", op: "=", value: t.text}, nil
+	case t.kind == tokIdent && p.tokens[p.pos+1].kind != tokOp:
+		// A bare word is too, e.g. -[calls]->.
+		p.next()
+		return comparison{field: "label", op: "=", value: t.text}, nil
+	case t.kind == tokIdent:
+		return p.parseComparison(fields)
+	}
+
+	return nil, fmt.Errorf("expected a predicate at position %d, found %s", t.pos, t)
+}
+
+func (p *parser) parseComparison(fields map[string]bool) (Predicate, error) {
+	field := p.next()
+	name := strings.ToLower(field.text)
+	if !fields[name] {
+		return nil, fmt.Errorf("unknown field %q at position %d", field.text, field.pos)
+	}
+
+	op, err := p.expect(tokOp, "an operator (=, !=, ~, =~, !~)")
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := p.expect(tokString, "a quoted string")
+	if err != nil {
+		return nil, err
+	}
+
+	c := comparison{field: name, op: op.text, value: value.text}
+	if op.text == "=~" || op.text == "!~" {
+		c.re, err = regexp.Compile(value.text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression %q: %w", value.text, err)
+		}
+	}
+
+	return c, nil
+}
+
+func (p *parser) isKeyword(word string) bool {
+	t := p.peek()
+	return t.kind == tokIdent && strings.EqualFold(t.text, word)
+}
@@ -0,0 +1,126 @@
+// Package remote publishes graphs to registries and fetches them back.
+// Fetched copies are kept under .tribal/remotes/<remote>, leaving the
+// working graphs alone, so they can be compared before anything changes.
+package remote
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tribal/tribal-cli/internal/client"
+	"github.com/tribal/tribal-cli/internal/config"
+	"github.com/tribal/tribal-cli/internal/graph"
+)
+
+// Dir returns the directory holding the graphs fetched from a remote.
+func Dir(name string) string {
+	return filepath.Join(config.ConfigDir, "remotes", name)
+}
+
+// Path returns where the fetched copy of a graph is kept.
+func Path(name, title string) string {
+	return filepath.Join(Dir(name), graph.Filename(title))
+}
+
+// Client returns a registry client for a remote, authenticated with the
+// login stored for its URL when there is one.
+func Client(cfg *config.Config, r *config.Remote) (*client.Client, error) {
+	c := client.NewClient(r.URL)
+	if err := c.ValidateURL(); err != nil {
+		return nil, fmt.Errorf("invalid registry URL: %w", err)
+	}
+	cred, err := cfg.Credentials(r.URL)
+	if err != nil {
+		return nil, err
+	}
+	if cred.Token != "" {
+		c.SetToken(cred.Token)
+	}
+	return c, nil
+}
+
+// Push publishes g, updating the registry graph with the given ID or
+// creating one when id is empty or the graph no longer exists there.
+func Push(c *client.Client, g *graph.Graph, id, message string, public bool) (*client.Graph, error) {
+	var description *string
+	if d := g.Description(); d != "" {
+		description = &d
+	}
+	nodes, edges := g.Nodes, g.Edges
+	if nodes == nil {
+		nodes = []client.Node{}
+	}
+	if edges == nil {
+		edges = []client.Edge{}
+	}
+
+	if id != "" {
+		req := client.UpdateGraphRequest{
+			Title:       &g.Title,
+			Description: description,
+			Nodes:       &nodes,
+			Edges:       &edges,
+			Metadata:    &g.Metadata,
+			Message:     message,
+		}
+		if public {
+			req.IsPublic = &public
+		}
+		rg, err := c.UpdateGraph(id, req)
+		if err == nil || !notFound(err) {
+			return rg, err
+		}
+	}
+
+	return c.CreateGraph(client.CreateGraphRequest{
+		Title:       g.Title,
+		Description: description,
+		Nodes:       nodes,
+		Edges:       edges,
+		Metadata:    g.Metadata,
+		IsPublic:    public,
+	})
+}
+
+// Fetch downloads a registry graph in the local representation.
+func Fetch(c *client.Client, id string) (*graph.Graph, error) {
+	rg, err := c.GetGraph(id)
+	if err != nil {
+		return nil, err
+	}
+	return FromRegistry(rg), nil
+}
+
+// FromRegistry converts a registry graph to the local representation.
+func FromRegistry(rg *client.Graph) *graph.Graph {
+	g := &graph.Graph{
+		ID:       rg.ID.String(),
+		Title:    rg.Title,
+		Nodes:    rg.Nodes,
+		Edges:    rg.Edges,
+		Metadata: rg.Metadata,
+	}
+	if g.Metadata == nil {
+		g.Metadata = make(map[string]interface{})
+	}
+	if rg.Description != nil && *rg.Description != "" {
+		g.Metadata["description"] = *rg.Description
+	}
+	return g
+}
+
+// Save stores the fetched copy of a graph from a remote.
+func Save(name string, g *graph.Graph) (string, error) {
+	if err := os.MkdirAll(Dir(name), 0755); err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", Dir(name), err)
+	}
+	path := Path(name, g.Title)
+	return path, g.Save(path)
+}
+
+// notFound reports whether a registry error is a 404.
+func notFound(err error) bool {
+	return strings.HasPrefix(err.Error(), "HTTP 404")
+}
@@ -0,0 +1,52 @@
+// Package repo locates the tribal repository a command runs in: the
+// nearest directory at or above the working directory that holds .tribal,
+// the way git finds .git.
+package repo
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/tribal/tribal-cli/internal/config"
+)
+
+// EnvDir names the environment variable that sets the repository root,
+// skipping discovery.
+const EnvDir = "TRIBAL_DIR"
+
+// Find returns the absolute path of the repository containing start, or
+// false when there is none. $TRIBAL_DIR, when set, is used as the root and
+// must hold a .tribal directory.
+func Find(start string) (string, bool, error) {
+	if dir := os.Getenv(EnvDir); dir != "" {
+		root, err := filepath.Abs(dir)
+		if err != nil {
+			return "", false, err
+		}
+		if !isRoot(root) {
+			return "", false, fmt.Errorf("%s=%s is not a tribal repository", EnvDir, dir)
+		}
+		return root, true, nil
+	}
+
+	dir, err := filepath.Abs(start)
+	if err != nil {
+		return "", false, err
+	}
+	for {
+		if isRoot(dir) {
+			return dir, true, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false, nil
+		}
+		dir = parent
+	}
+}
+
+func isRoot(dir string) bool {
+	info, err := os.Stat(filepath.Join(dir, config.ConfigDir))
+	return err == nil && info.IsDir()
+}
@@ -0,0 +1,427 @@
+// Package scan builds graphs from the structure of source code.
+package scan
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/tribal/tribal-cli/internal/anchor"
+	"github.com/tribal/tribal-cli/internal/client"
+	"github.com/tribal/tribal-cli/internal/graph"
+	"github.com/tribal/tribal-cli/internal/importer"
+)
+
+// Edge labels used by the Go scanner.
+const (
+	LabelImports    = "imports"
+	LabelDeclares   = "declares"
+	LabelImplements = "implements"
+)
+
+// GoOptions control what the Go scanner adds to the graph.
+type GoOptions struct {
+	// Types adds a node for every exported type, linked to its package, and
+	// "implements" edges from types to the interfaces they satisfy.
+	Types bool
+}
+
+// goPackage collects what the scanner needs from one package directory.
+type goPackage struct {
+	dir     string
+	name    string
+	doc     string
+	imports map[string]bool
+	types   []*goType
+}
+
+type goType struct {
+	pkg       *goPackage
+	name      string
+	doc       string
+	iface     *ast.InterfaceType
+	methods   map[string]string // method name to normalized signature
+	required  map[string]string // interface methods, including embedded ones
+	embedded  []string          // names of embedded interfaces
+	startLine int
+	endLine   int
+	file      string
+}
+
+// Go scans the Go packages under root that match the patterns and returns a
+// graph with one node per package, labelled with its directory, and an
+// "imports" edge for every import between scanned packages. Patterns are
+// directories relative to root, optionally ending in /... to include every
+// package below them. Package doc comments become node markup and every node
+// is anchored to its code.
+func Go(root string, patterns []string, opts GoOptions) (*graph.Graph, error) {
+	if len(patterns) == 0 {
+		patterns = []string{"./..."}
+	}
+	module := anchor.ModulePath(root)
+
+	packages := make(map[string]*goPackage)
+	var dirs []string
+	fset := token.NewFileSet()
+	err := anchor.WalkGoFiles(root, func(rel, pkgName string) error {
+		dir := filepath.ToSlash(filepath.Dir(rel))
+		if !matchPattern(dir, patterns) || pkgName == "documentation" {
+			return nil
+		}
+
+		f, err := parser.ParseFile(fset, filepath.Join(root, rel), nil, parser.ParseComments|parser.SkipObjectResolution)
+		if err != nil {
+			return nil
+		}
+
+		pkg, ok := packages[dir]
+		if !ok {
+			pkg = &goPackage{dir: dir, name: pkgName, imports: make(map[string]bool)}
+			packages[dir] = pkg
+			dirs = append(dirs, dir)
+		}
+		if pkgName != pkg.name {
+			// Files for another package in the same directory, such as
+			// a main package behind a build tag.
+			return nil
+		}
+		if f.Doc != nil && (pkg.doc == "" || filepath.Base(rel) == "doc.go") {
+			pkg.doc = strings.TrimSpace(f.Doc.Text())
+		}
+
+		for _, imp := range f.Imports {
+			path := strings.Trim(imp.Path.Value, `"`)
+			if module != "" && (path == module || strings.HasPrefix(path, module+"/")) {
+				pkg.imports[strings.TrimPrefix(strings.TrimPrefix(path, module), "/")] = true
+			}
+		}
+		if opts.Types {
+			collectTypes(fset, f, rel, pkg)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(dirs) == 0 {
+		return nil, fmt.Errorf("no Go packages match %s", strings.Join(patterns, " "))
+	}
+	sort.Strings(dirs)
+
+	g := &graph.Graph{Nodes: []client.Node{}, Edges: []client.Edge{}, Metadata: map[string]interface{}{}}
+	ids := make(map[string]string)
+	addNode := func(key, label, doc string, a client.Anchor) {
+		n := client.Node{ID: fmt.Sprintf("n%d", len(g.Nodes)+1), Label: label, Anchors: []client.Anchor{a}}
+		if doc != "" {
+			n.Markup = &doc
+		}
+		ids[key] = n.ID
+		g.Nodes = append(g.Nodes, n)
+	}
+	addEdge := func(from, to, label string) {
+		l := label
+		g.Edges = append(g.Edges, client.Edge{ID: fmt.Sprintf("e%d", len(g.Edges)+1), Source: ids[from], Target: ids[to], Directed: true, Label: &l})
+	}
+
+	for _, dir := range dirs {
+		pkg := packages[dir]
+		label := dir
+		if dir == "." {
+			label = module
+			if label == "" {
+				label = pkg.name
+			}
+		}
+		addNode(dir, label, pkg.doc, client.Anchor{Path: dir, Package: true})
+	}
+	for _, dir := range dirs {
+		imports := make([]string, 0, len(packages[dir].imports))
+		for imp := range packages[dir].imports {
+			if imp == "" {
+				imp = "."
+			}
+			if _, ok := packages[imp]; ok && imp != dir {
+				imports = append(imports, imp)
+			}
+		}
+		sort.Strings(imports)
+		for _, imp := range imports {
+			addEdge(dir, imp, LabelImports)
+		}
+	}
+
+	if opts.Types {
+		var types []*goType
+		for _, dir := range dirs {
+			for _, t := range packages[dir].types {
+				key := t.pkg.dir + "#" + t.name
+				symbol := t.pkg.name + "." + t.name
+				addNode(key, symbol, t.doc, client.Anchor{Path: t.file, StartLine: t.startLine, EndLine: t.endLine, Symbol: symbol})
+				addEdge(dir, key, LabelDeclares)
+				types = append(types, t)
+			}
+		}
+		resolveEmbedded(types)
+		for _, t := range types {
+			for _, iface := range types {
+				if t != iface && iface.iface != nil && implements(t, iface) {
+					addEdge(t.pkg.dir+"#"+t.name, iface.pkg.dir+"#"+iface.name, LabelImplements)
+				}
+			}
+		}
+	}
+
+	return g, nil
+}
+
+// matchPattern reports whether a package directory matches any pattern.
+func matchPattern(dir string, patterns []string) bool {
+	for _, p := range patterns {
+		p = filepath.ToSlash(filepath.Clean(p))
+		recursive := false
+		if p == "..." || strings.HasSuffix(p, "/...") {
+			recursive = true
+			p = strings.TrimSuffix(strings.TrimSuffix(p, "..."), "/")
+			if p == "" {
+				p = "."
+			}
+		}
+		if dir == p || recursive && (p == "." || strings.HasPrefix(dir, p+"/")) {
+			return true
+		}
+	}
+	return false
+}
+
+// collectTypes records the exported types of a file and the methods
+// declared on them.
+func collectTypes(fset *token.FileSet, f *ast.File, rel string, pkg *goPackage) {
+	byName := func(name string) *goType {
+		for _, t := range pkg.types {
+			if t.name == name {
+				return t
+			}
+		}
+		return nil
+	}
+
+	for _, decl := range f.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			ts := spec.(*ast.TypeSpec)
+			if !ts.Name.IsExported() || ts.Assign.IsValid() {
+				continue
+			}
+
+			t := byName(ts.Name.Name)
+			if t == nil {
+				t = &goType{pkg: pkg, name: ts.Name.Name, methods: make(map[string]string)}
+				pkg.types = append(pkg.types, t)
+			}
+			t.file = rel
+			from, to := gen.Pos(), gen.End()
+			if gen.Lparen.IsValid() {
+				from, to = ts.Pos(), ts.End()
+			}
+			t.startLine, t.endLine = fset.Position(from).Line, fset.Position(to).Line
+
+			doc := ts.Doc
+			if doc == nil && !gen.Lparen.IsValid() {
+				doc = gen.Doc
+			}
+			if doc != nil {
+				t.doc = strings.TrimSpace(doc.Text())
+			}
+
+			if iface, ok := ts.Type.(*ast.InterfaceType); ok {
+				t.iface = iface
+				t.required = make(map[string]string)
+				for _, m := range iface.Methods.List {
+					switch ft := m.Type.(type) {
+					case *ast.FuncType:
+						for _, name := range m.Names {
+							t.required[name.Name] = signature(ft)
+						}
+					case *ast.Ident:
+						t.embedded = append(t.embedded, ft.Name)
+					case *ast.SelectorExpr:
+						t.embedded = append(t.embedded, ft.Sel.Name)
+					}
+				}
+			}
+		}
+	}
+
+	for _, decl := range f.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Recv == nil {
+			continue
+		}
+		receiver := anchor.ReceiverType(fn)
+		if !ast.IsExported(receiver) {
+			continue
+		}
+		t := byName(receiver)
+		if t == nil {
+			// The type is declared in a file not seen yet.
+			t = &goType{pkg: pkg, name: receiver, methods: make(map[string]string)}
+			pkg.types = append(pkg.types, t)
+		}
+		t.methods[fn.Name.Name] = signature(fn.Type)
+	}
+}
+
+// resolveEmbedded adds the methods of embedded interfaces, found by name
+// among the scanned types, to the interfaces that embed them.
+func resolveEmbedded(types []*goType) {
+	byName := make(map[string]*goType)
+	for _, t := range types {
+		if t.iface != nil {
+			byName[t.name] = t
+		}
+	}
+
+	var expand func(t *goType, seen map[*goType]bool)
+	expand = func(t *goType, seen map[*goType]bool) {
+		seen[t] = true
+		for _, name := range t.embedded {
+			e, ok := byName[name]
+			if !ok || seen[e] {
+				continue
+			}
+			expand(e, seen)
+			for m, sig := range e.required {
+				t.required[m] = sig
+			}
+		}
+	}
+	for _, t := range types {
+		if t.iface != nil {
+			expand(t, make(map[*goType]bool))
+		}
+	}
+}
+
+// implements reports whether t has every method iface requires, comparing
+// signatures with package qualifiers removed. Interfaces without methods
+// are never reported.
+func implements(t, iface *goType) bool {
+	if t.iface != nil || len(iface.required) == 0 {
+		return false
+	}
+	for name, sig := range iface.required {
+		if t.methods[name] != sig {
+			return false
+		}
+	}
+	return true
+}
+
+// signature prints a function type without parameter names or package
+// qualifiers, so the same method declared in different packages compares
+// equal.
+func signature(ft *ast.FuncType) string {
+	fields := func(list *ast.FieldList) string {
+		if list == nil {
+			return ""
+		}
+		var parts []string
+		for _, f := range list.List {
+			n := len(f.Names)
+			if n == 0 {
+				n = 1
+			}
+			for i := 0; i < n; i++ {
+				parts = append(parts, typeString(f.Type))
+			}
+		}
+		return strings.Join(parts, ",")
+	}
+	return "(" + fields(ft.Params) + ")(" + fields(ft.Results) + ")"
+}
+
+func typeString(expr ast.Expr) string {
+	expr = unqualify(expr)
+	var b bytes.Buffer
+	printer.Fprint(&b, token.NewFileSet(), expr)
+	return b.String()
+}
+
+// unqualify returns a copy of a type expression with pkg.Name selectors
+// replaced by Name.
+func unqualify(expr ast.Expr) ast.Expr {
+	switch e := expr.(type) {
+	case *ast.SelectorExpr:
+		return e.Sel
+	case *ast.StarExpr:
+		return &ast.StarExpr{X: unqualify(e.X)}
+	case *ast.ArrayType:
+		return &ast.ArrayType{Len: e.Len, Elt: unqualify(e.Elt)}
+	case *ast.MapType:
+		return &ast.MapType{Key: unqualify(e.Key), Value: unqualify(e.Value)}
+	case *ast.ChanType:
+		return &ast.ChanType{Dir: e.Dir, Value: unqualify(e.Value)}
+	case *ast.Ellipsis:
+		return &ast.Ellipsis{Elt: unqualify(e.Elt)}
+	}
+	return expr
+}
+
+// Merge adds a scanned graph to dst without overwriting anything written by
+// hand. Scanned nodes are matched to existing nodes by their code anchor, or
+// failing that by label; matched nodes keep their label and markup, gaining
+// only the markup and anchors they were missing.
+func Merge(dst, src *graph.Graph) importer.MergeStats {
+	for i := range src.Nodes {
+		n := &src.Nodes[i]
+		if existing := findByAnchor(dst, n.Anchors); existing != nil {
+			n.Label = existing.Label
+		}
+	}
+
+	stats := importer.Merge(dst, src)
+
+	for _, n := range src.Nodes {
+		existing := dst.FindByLabel(n.Label)
+		if existing == nil {
+			continue
+		}
+		for _, a := range n.Anchors {
+			if findAnchor(existing.Anchors, a) < 0 {
+				existing.Anchors = append(existing.Anchors, a)
+			}
+		}
+	}
+	return stats
+}
+
+// findByAnchor returns the node with an anchor to the same path, symbol and
+// kind as one of anchors, ignoring line numbers.
+func findByAnchor(g *graph.Graph, anchors []client.Anchor) *client.Node {
+	for i := range g.Nodes {
+		for _, a := range anchors {
+			if findAnchor(g.Nodes[i].Anchors, a) >= 0 {
+				return &g.Nodes[i]
+			}
+		}
+	}
+	return nil
+}
+
+func findAnchor(anchors []client.Anchor, a client.Anchor) int {
+	for i, existing := range anchors {
+		if existing.Path == a.Path && existing.Symbol == a.Symbol && existing.Package == a.Package {
+			return i
+		}
+	}
+	return -1
+}
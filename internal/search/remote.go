@@ -0,0 +1,162 @@
+package search
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/tribal/tribal-cli/internal/client"
+	"github.com/tribal/tribal-cli/internal/graph"
+)
+
+const DefaultLimit = 20
+
+type RemoteOptions struct {
+	// Public restricts results to public graphs. With an empty query the
+	// public graph listing is returned instead of search results.
+	Public bool
+	Owner  *uuid.UUID
+	// Limit is the maximum number of graphs to return across all pages.
+	Limit  int
+	Offset int
+}
+
+// Remote queries the registry, following HasMore until Limit graphs have been
+// collected. It returns the graphs and the total reported by the registry.
+func Remote(c *client.Client, query string, opts RemoteOptions) ([]client.Graph, int, error) {
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+
+	var graphs []client.Graph
+	offset := opts.Offset
+	total := 0
+
+	for len(graphs) < limit {
+		pageSize := limit - len(graphs)
+
+		var page []client.Graph
+		var hasMore bool
+		if query == "" && opts.Public {
+			var err error
+			page, total, hasMore, err = c.GetPublicGraphs(pageSize, offset)
+			if err != nil {
+				return nil, 0, fmt.Errorf("failed to list public graphs: %w", err)
+			}
+		} else {
+			req := client.SearchRequest{
+				Query:  query,
+				UserID: opts.Owner,
+				Limit:  pageSize,
+				Offset: offset,
+			}
+			if opts.Public {
+				public := true
+				req.IsPublic = &public
+			}
+
+			resp, err := c.SearchGraphs(req)
+			if err != nil {
+				return nil, 0, fmt.Errorf("remote search failed: %w", err)
+			}
+			page, total, hasMore = resp.Graphs, resp.Total, resp.HasMore
+		}
+
+		graphs = append(graphs, page...)
+		offset += len(page)
+
+		if !hasMore || len(page) == 0 {
+			break
+		}
+	}
+
+	if len(graphs) > limit {
+		graphs = graphs[:limit]
+	}
+
+	return graphs, total, nil
+}
+
+// RemoteResults converts registry graphs into graph-level results. Graphs are
+// scored locally against the query so they rank alongside local results.
+func RemoteResults(graphs []client.Graph, query string, opts Options) []Result {
+	if opts.Kind != "" && opts.Kind != KindGraph {
+		return nil
+	}
+
+	terms := Terms(query)
+	var results []Result
+	for _, g := range graphs {
+		if opts.Graph != "" && !strings.EqualFold(g.Title, opts.Graph) {
+			continue
+		}
+
+		score, _ := scoreText(g.Title, terms, titleWeight)
+		descScore, desc := scoreText(deref(g.Description), terms, descriptionWeight)
+		if descScore == 0 && g.Description != nil {
+			desc = Snippet{Text: *g.Description}
+		}
+
+		results = append(results, Result{
+			Kind:       KindGraph,
+			Source:     SourceRemote,
+			GraphID:    g.ID.String(),
+			GraphTitle: g.Title,
+			Label:      g.Title,
+			Score:      score + descScore,
+			Snippet:    desc,
+		})
+	}
+
+	return results
+}
+
+// Merge combines local and remote results. A remote graph is the same as a
+// local one when their IDs match, or, for local graphs that have never been
+// pushed, when their titles match. Such graphs are reported once with
+// SourceBoth.
+func Merge(local []Result, files []graph.File, remote []Result) []Result {
+	merged := append([]Result(nil), local...)
+
+	for _, r := range remote {
+		f := findLocal(files, r)
+		if f == nil {
+			merged = append(merged, r)
+			continue
+		}
+
+		found := false
+		for i := range merged {
+			if merged[i].GraphFile == f.Path {
+				merged[i].Source = SourceBoth
+				merged[i].GraphID = r.GraphID
+				found = found || merged[i].Kind == KindGraph
+			}
+		}
+
+		if !found {
+			r.Source = SourceBoth
+			r.GraphFile = f.Path
+			merged = append(merged, r)
+		}
+	}
+
+	Sort(merged)
+	return merged
+}
+
+func findLocal(files []graph.File, r Result) *graph.File {
+	for i, f := range files {
+		if f.Graph.ID != "" {
+			if f.Graph.ID == r.GraphID {
+				return &files[i]
+			}
+			continue
+		}
+		if strings.EqualFold(f.Graph.Title, r.GraphTitle) {
+			return &files[i]
+		}
+	}
+	return nil
+}
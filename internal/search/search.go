@@ -0,0 +1,372 @@
+package search
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/tribal/tribal-cli/internal/graph"
+)
+
+// Kind identifies what a search result points at.
+type Kind string
+
+const (
+	KindGraph Kind = "graph"
+	KindNode  Kind = "node"
+	KindEdge  Kind = "edge"
+)
+
+// Weights applied per matched query term, by field.
+const (
+	titleWeight       = 1
+	descriptionWeight = 2
+	labelWeight       = 3
+	markupWeight      = 1
+)
+
+const snippetRadius = 60
+
+// Source records where a result was found.
+type Source string
+
+const (
+	SourceLocal  Source = "local"
+	SourceRemote Source = "remote"
+	SourceBoth   Source = "local+remote"
+)
+
+type Options struct {
+	// Kind restricts results to a single kind. Empty means all kinds.
+	Kind Kind
+	// Graph restricts results to the graph with this title (case-insensitive).
+	Graph string
+}
+
+type Result struct {
+	Kind       Kind
+	Source     Source
+	GraphID    string
+	GraphTitle string
+	// GraphFile is empty for graphs that only exist remotely.
+	GraphFile string
+	// ID is the node or edge ID. Empty for graph results.
+	ID      string
+	Label   string
+	Score   int
+	Snippet Snippet
+}
+
+// Snippet is an excerpt of the matched text. Highlights are byte offsets into
+// Text covering each matched word.
+type Snippet struct {
+	Text       string
+	Highlights []Span
+}
+
+type Span struct {
+	Start int
+	End   int
+}
+
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "for": true, "in": true,
+	"is": true, "of": true, "on": true, "or": true, "the": true, "to": true,
+	"with": true,
+}
+
+func ParseKind(s string) (Kind, bool) {
+	switch Kind(strings.ToLower(s)) {
+	case "":
+		return "", true
+	case KindGraph:
+		return KindGraph, true
+	case KindNode:
+		return KindNode, true
+	case KindEdge:
+		return KindEdge, true
+	}
+	return "", false
+}
+
+// Local searches every graph under .tribal/graphs.
+func Local(query string, opts Options) ([]Result, error) {
+	files, err := graph.LoadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := CheckGraph(files, opts.Graph); err != nil {
+		return nil, err
+	}
+	return Files(files, query, opts), nil
+}
+
+// CheckGraph returns an error when title is set and none of the files holds
+// a graph with that title (case-insensitive).
+func CheckGraph(files []graph.File, title string) error {
+	if title == "" {
+		return nil
+	}
+	for _, f := range files {
+		if strings.EqualFold(f.Graph.Title, title) {
+			return nil
+		}
+	}
+	return fmt.Errorf("no graph titled %q", title)
+}
+
+// Files searches the given local graph files.
+func Files(files []graph.File, query string, opts Options) []Result {
+	terms := Terms(query)
+	var results []Result
+	for _, f := range files {
+		if opts.Graph != "" && !strings.EqualFold(f.Graph.Title, opts.Graph) {
+			continue
+		}
+		results = append(results, Graph(f.Graph, f.Path, terms, opts)...)
+	}
+
+	Sort(results)
+	return results
+}
+
+// Graph searches a single graph for the given terms.
+func Graph(g *graph.Graph, file string, terms []string, opts Options) []Result {
+	var results []Result
+
+	if opts.Kind == "" || opts.Kind == KindGraph {
+		score, _ := scoreText(g.Title, terms, titleWeight)
+		descScore, desc := scoreText(g.Description(), terms, descriptionWeight)
+		if score+descScore > 0 {
+			results = append(results, Result{
+				Kind:       KindGraph,
+				Source:     SourceLocal,
+				GraphID:    g.ID,
+				GraphTitle: g.Title,
+				GraphFile:  file,
+				Label:      g.Title,
+				Score:      score + descScore,
+				Snippet:    desc,
+			})
+		}
+	}
+
+	if opts.Kind == "" || opts.Kind == KindNode {
+		for _, n := range g.Nodes {
+			labelScore, label := scoreText(n.Label, terms, labelWeight)
+			markupScore, markup := scoreText(deref(n.Markup), terms, markupWeight)
+			if labelScore+markupScore == 0 {
+				continue
+			}
+			snippet := markup
+			if markupScore == 0 {
+				snippet = label
+			}
+			results = append(results, Result{
+				Kind:       KindNode,
+				Source:     SourceLocal,
+				GraphID:    g.ID,
+				GraphTitle: g.Title,
+				GraphFile:  file,
+				ID:         n.ID,
+				Label:      n.Label,
+				Score:      labelScore + markupScore,
+				Snippet:    snippet,
+			})
+		}
+	}
+
+	if opts.Kind == "" || opts.Kind == KindEdge {
+		for _, e := range g.Edges {
+			labelScore, label := scoreText(deref(e.Label), terms, labelWeight)
+			markupScore, markup := scoreText(deref(e.Markup), terms, markupWeight)
+			if labelScore+markupScore == 0 {
+				continue
+			}
+			snippet := markup
+			if markupScore == 0 {
+				snippet = label
+			}
+			results = append(results, Result{
+				Kind:       KindEdge,
+				Source:     SourceLocal,
+				GraphID:    g.ID,
+				GraphTitle: g.Title,
+				GraphFile:  file,
+				ID:         e.ID,
+				Label:      g.EdgeLabel(e),
+				Score:      labelScore + markupScore,
+				Snippet:    snippet,
+			})
+		}
+	}
+
+	return results
+}
+
+// Sort orders results by descending score, then graph title and kind.
+func Sort(results []Result) {
+	order := map[Kind]int{KindGraph: 0, KindNode: 1, KindEdge: 2}
+	sort.SliceStable(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if a.GraphTitle != b.GraphTitle {
+			return a.GraphTitle < b.GraphTitle
+		}
+		return order[a.Kind] < order[b.Kind]
+	})
+}
+
+// Terms splits a query into normalized search terms, dropping stop words.
+func Terms(query string) []string {
+	seen := make(map[string]bool)
+	var terms []string
+	for _, w := range words(query) {
+		lower := strings.ToLower(query[w.Start:w.End])
+		if stopWords[lower] || seen[lower] {
+			continue
+		}
+		seen[lower] = true
+		terms = append(terms, lower)
+	}
+	return terms
+}
+
+// Matches reports whether text contains a word matching any of the terms.
+func Matches(text string, terms []string) bool {
+	score, _ := scoreText(text, terms, 1)
+	return score > 0
+}
+
+// scoreText returns weight times the number of distinct terms found in text,
+// along with a snippet highlighting every matched word.
+func scoreText(text string, terms []string, weight int) (int, Snippet) {
+	if text == "" || len(terms) == 0 {
+		return 0, Snippet{}
+	}
+
+	text = strings.Join(strings.Fields(text), " ")
+	stems := make([]string, len(terms))
+	for i, t := range terms {
+		stems[i] = stem(t)
+	}
+
+	matched := make(map[int]bool)
+	var spans []Span
+	for _, w := range words(text) {
+		ws := stem(strings.ToLower(text[w.Start:w.End]))
+		hit := false
+		for i, ts := range stems {
+			if stemsMatch(ws, ts) {
+				matched[i] = true
+				hit = true
+			}
+		}
+		if hit {
+			spans = append(spans, w)
+		}
+	}
+
+	if len(matched) == 0 {
+		return 0, Snippet{}
+	}
+
+	return len(matched) * weight, excerpt(text, spans)
+}
+
+// excerpt trims text to a window around the first highlight, shifting the
+// remaining highlights accordingly.
+func excerpt(text string, spans []Span) Snippet {
+	start := spans[0].Start - snippetRadius
+	end := spans[0].End + snippetRadius
+	prefix, suffix := "", ""
+
+	if start <= 0 {
+		start = 0
+	} else {
+		if i := strings.IndexByte(text[start:spans[0].Start], ' '); i >= 0 {
+			start += i + 1
+		}
+		prefix = "..."
+	}
+	if end >= len(text) {
+		end = len(text)
+	} else {
+		if i := strings.LastIndexByte(text[spans[0].End:end], ' '); i >= 0 {
+			end = spans[0].End + i
+		}
+		for end < len(text) && !utf8.RuneStart(text[end]) {
+			end++
+		}
+		suffix = "..."
+	}
+
+	snippet := Snippet{Text: prefix + text[start:end] + suffix}
+	for _, s := range spans {
+		if s.Start < start || s.End > end {
+			continue
+		}
+		offset := len(prefix) - start
+		snippet.Highlights = append(snippet.Highlights, Span{Start: s.Start + offset, End: s.End + offset})
+	}
+
+	return snippet
+}
+
+func words(text string) []Span {
+	var spans []Span
+	start := -1
+	for i, r := range text {
+		isWord := unicode.IsLetter(r) || unicode.IsDigit(r)
+		if isWord && start < 0 {
+			start = i
+		} else if !isWord && start >= 0 {
+			spans = append(spans, Span{Start: start, End: i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		spans = append(spans, Span{Start: start, End: len(text)})
+	}
+	return spans
+}
+
+// stem strips common English suffixes so that e.g. "limiter" and "limiting"
+// compare equal. It is deliberately crude.
+func stem(word string) string {
+	for _, suffix := range []string{"ations", "ation", "ings", "ing", "ers", "er", "ies", "ied", "ed", "es", "s", "ly"} {
+		if strings.HasSuffix(word, suffix) && len(word)-len(suffix) >= 3 {
+			word = word[:len(word)-len(suffix)]
+			if suffix == "ies" || suffix == "ied" {
+				word += "y"
+			}
+			break
+		}
+	}
+	if strings.HasSuffix(word, "e") && len(word) > 3 {
+		word = word[:len(word)-1]
+	}
+	return word
+}
+
+func stemsMatch(a, b string) bool {
+	if a == b {
+		return true
+	}
+	if len(a) >= 4 && len(b) >= 4 {
+		return strings.HasPrefix(a, b) || strings.HasPrefix(b, a)
+	}
+	return false
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,704 @@
+package tui
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/tribal/tribal-cli/internal/client"
+	"github.com/tribal/tribal-cli/internal/graph"
+)
+
+// Actions are the repository operations the UI can trigger. They run with
+// the terminal restored, so they may print freely.
+type Actions struct {
+	// Stage makes the graph stored at path the current graph and stages it.
+	Stage func(path string) error
+	// Commit commits the staged graph with the given message.
+	Commit func(message string) error
+}
+
+type view int
+
+const (
+	viewGraphs view = iota
+	viewGraph
+)
+
+type focus int
+
+const (
+	focusNodes focus = iota
+	focusEdges
+)
+
+// maxUndo bounds the number of snapshots kept per open graph.
+const maxUndo = 100
+
+type prompt struct {
+	label string
+	value []rune
+	// single prompts submit on the first keypress, for y/n questions.
+	single bool
+	submit func(value string)
+}
+
+type app struct {
+	term    *terminal
+	actions Actions
+
+	view  view
+	files []graph.File
+	// graphIndex is the selected row of the graph list.
+	graphIndex int
+
+	file         *graph.File
+	nodeIndex    int
+	edgeIndex    int
+	focus        focus
+	detailScroll int
+	undo         []*graph.Graph
+
+	prompt    *prompt
+	status    string
+	statusErr bool
+	showHelp  bool
+	quit      bool
+}
+
+// Run starts the terminal UI and blocks until the user quits.
+func Run(actions Actions) error {
+	t, err := openTerminal()
+	if err != nil {
+		return err
+	}
+	defer t.suspend()
+
+	a := &app{term: t, actions: actions}
+	if err := a.reloadGraphs(); err != nil {
+		return err
+	}
+
+	for !a.quit {
+		a.render()
+		key, err := t.readKey()
+		if err != nil {
+			return err
+		}
+		a.handle(key)
+	}
+
+	return nil
+}
+
+func (a *app) reloadGraphs() error {
+	files, err := graph.LoadAll()
+	if err != nil {
+		return err
+	}
+	a.files = files
+	if a.graphIndex >= len(files) {
+		a.graphIndex = len(files) - 1
+	}
+	if a.graphIndex < 0 {
+		a.graphIndex = 0
+	}
+	return nil
+}
+
+func (a *app) setStatus(format string, args ...interface{}) {
+	a.status = fmt.Sprintf(format, args...)
+	a.statusErr = false
+}
+
+func (a *app) setError(err error) {
+	a.status = err.Error()
+	a.statusErr = true
+}
+
+func (a *app) ask(label, initial string, submit func(string)) {
+	a.prompt = &prompt{label: label, value: []rune(initial), submit: submit}
+}
+
+func (a *app) confirm(question string, yes func()) {
+	a.prompt = &prompt{label: question + " (y/n)", single: true, submit: func(v string) {
+		if strings.EqualFold(v, "y") {
+			yes()
+		} else {
+			a.setStatus("Cancelled")
+		}
+	}}
+}
+
+func (a *app) handle(key Key) {
+	if key.Name == keyCtrlC {
+		a.quit = true
+		return
+	}
+
+	if a.prompt != nil {
+		a.handlePrompt(key)
+		return
+	}
+
+	if a.showHelp {
+		a.showHelp = false
+		return
+	}
+
+	a.status = ""
+	if key.Rune == '?' {
+		a.showHelp = true
+		return
+	}
+
+	switch a.view {
+	case viewGraphs:
+		a.handleGraphsKey(key)
+	case viewGraph:
+		a.handleGraphKey(key)
+	}
+}
+
+func (a *app) handlePrompt(key Key) {
+	p := a.prompt
+	if p.single {
+		a.prompt = nil
+		if key.Rune != 0 {
+			p.submit(string(key.Rune))
+		} else {
+			a.setStatus("Cancelled")
+		}
+		return
+	}
+
+	switch {
+	case key.Name == keyEscape:
+		a.prompt = nil
+		a.setStatus("Cancelled")
+	case key.Name == keyEnter:
+		a.prompt = nil
+		p.submit(strings.TrimSpace(string(p.value)))
+	case key.Name == keyBackspace:
+		if len(p.value) > 0 {
+			p.value = p.value[:len(p.value)-1]
+		}
+	case key.Rune != 0:
+		p.value = append(p.value, key.Rune)
+	}
+}
+
+func (a *app) handleGraphsKey(key Key) {
+	switch {
+	case key.Name == keyUp || key.Rune == 'k':
+		if a.graphIndex > 0 {
+			a.graphIndex--
+		}
+	case key.Name == keyDown || key.Rune == 'j':
+		if a.graphIndex < len(a.files)-1 {
+			a.graphIndex++
+		}
+	case key.Name == keyEnter || key.Name == keyRight || key.Rune == 'l':
+		if len(a.files) > 0 {
+			a.openGraph(a.files[a.graphIndex])
+		}
+	case key.Rune == 'n':
+		a.ask("New graph title:", "", a.createGraph)
+	case key.Rune == 'r':
+		if err := a.reloadGraphs(); err != nil {
+			a.setError(err)
+		} else {
+			a.setStatus("Reloaded %d graphs", len(a.files))
+		}
+	case key.Rune == 'q' || key.Name == keyEscape:
+		a.quit = true
+	}
+}
+
+func (a *app) openGraph(f graph.File) {
+	// Re-read from disk so edits made outside the UI are picked up.
+	g, err := graph.Load(f.Path)
+	if err != nil {
+		a.setError(err)
+		return
+	}
+	a.file = &graph.File{Path: f.Path, Graph: g}
+	a.view = viewGraph
+	a.nodeIndex, a.edgeIndex, a.detailScroll = 0, 0, 0
+	a.focus = focusNodes
+	a.undo = nil
+}
+
+func (a *app) createGraph(title string) {
+	if title == "" {
+		a.setStatus("Cancelled")
+		return
+	}
+
+	path := graph.PathFor(title)
+	if _, err := os.Stat(path); err == nil {
+		a.setError(fmt.Errorf("graph %q already exists", title))
+		return
+	}
+	if err := os.MkdirAll(graph.Dir(), 0755); err != nil {
+		a.setError(err)
+		return
+	}
+
+	g := &graph.Graph{
+		Title: title,
+		Metadata: map[string]interface{}{
+			"created": time.Now().Format(time.RFC3339),
+		},
+	}
+	if err := g.Save(path); err != nil {
+		a.setError(err)
+		return
+	}
+
+	a.reloadGraphs()
+	for i, f := range a.files {
+		if f.Path == path {
+			a.graphIndex = i
+		}
+	}
+	a.openGraph(graph.File{Path: path, Graph: g})
+	a.setStatus("Created graph %s", title)
+}
+
+func (a *app) selectedNode() *client.Node {
+	g := a.file.Graph
+	if a.nodeIndex < 0 || a.nodeIndex >= len(g.Nodes) {
+		return nil
+	}
+	return &g.Nodes[a.nodeIndex]
+}
+
+func (a *app) selectedNeighbor() *graph.Neighbor {
+	n := a.selectedNode()
+	if n == nil {
+		return nil
+	}
+	neighbors := a.file.Graph.Neighbors(n.ID)
+	if a.edgeIndex < 0 || a.edgeIndex >= len(neighbors) {
+		return nil
+	}
+	return &neighbors[a.edgeIndex]
+}
+
+func (a *app) handleGraphKey(key Key) {
+	switch {
+	case key.Name == keyUp || key.Rune == 'k':
+		a.move(-1)
+	case key.Name == keyDown || key.Rune == 'j':
+		a.move(1)
+	case key.Name == keyPageUp:
+		if a.detailScroll > 0 {
+			a.detailScroll -= 5
+		}
+		if a.detailScroll < 0 {
+			a.detailScroll = 0
+		}
+	case key.Name == keyPageDown:
+		a.detailScroll += 5
+	case key.Name == keyTab || key.Name == keyRight || key.Rune == 'l':
+		if a.focus == focusNodes && a.selectedNeighbor() != nil {
+			a.focus = focusEdges
+		} else if key.Name == keyTab {
+			a.focus = focusNodes
+		}
+	case key.Name == keyLeft || key.Rune == 'h':
+		if a.focus == focusEdges {
+			a.focus = focusNodes
+		} else {
+			a.view = viewGraphs
+			a.reloadGraphs()
+		}
+	case key.Name == keyEscape || key.Rune == 'q':
+		if a.focus == focusEdges {
+			a.focus = focusNodes
+			return
+		}
+		a.view = viewGraphs
+		a.reloadGraphs()
+	case key.Name == keyEnter:
+		if nb := a.selectedNeighbor(); a.focus == focusEdges && nb != nil {
+			a.selectNode(nb.Node.ID)
+		}
+	case key.Rune == 'a':
+		a.ask("New node label:", "", a.addNode)
+	case key.Rune == 'e':
+		a.editLabel()
+	case key.Rune == 'm':
+		a.editMarkup()
+	case key.Rune == 'd':
+		a.deleteSelected()
+	case key.Rune == 'c':
+		if n := a.selectedNode(); n != nil {
+			a.ask(fmt.Sprintf("Connect %s to (node ID or label):", n.Label), "", a.connect)
+		}
+	case key.Rune == 'x':
+		if nb := a.selectedNeighbor(); a.focus == focusEdges && nb != nil {
+			id := nb.Edge.ID
+			a.mutate("Toggled edge direction", func(g *graph.Graph) error {
+				e := g.Edge(id)
+				e.Directed = !e.Directed
+				return nil
+			})
+		}
+	case key.Rune == 'u':
+		a.undoLast()
+	case key.Rune == 's':
+		a.stage()
+	case key.Rune == 'C':
+		a.ask("Commit message:", "", a.commit)
+	}
+
+	// Undo replaces the open graph, so read it again after the key is handled.
+	a.nodeIndex = clamp(a.nodeIndex, 0, len(a.file.Graph.Nodes)-1)
+}
+
+func (a *app) move(delta int) {
+	if a.focus == focusEdges {
+		n := a.selectedNode()
+		if n == nil {
+			return
+		}
+		count := len(a.file.Graph.Neighbors(n.ID))
+		a.edgeIndex = clamp(a.edgeIndex+delta, 0, count-1)
+		return
+	}
+
+	a.nodeIndex = clamp(a.nodeIndex+delta, 0, len(a.file.Graph.Nodes)-1)
+	a.edgeIndex, a.detailScroll = 0, 0
+}
+
+func (a *app) selectNode(id string) {
+	for i, n := range a.file.Graph.Nodes {
+		if n.ID == id {
+			a.nodeIndex = i
+			a.edgeIndex, a.detailScroll = 0, 0
+			a.focus = focusNodes
+			return
+		}
+	}
+}
+
+// mutate applies fn to the open graph, saving the previous version for undo
+// and writing the result to disk. The graph is left untouched if fn fails.
+func (a *app) mutate(description string, fn func(g *graph.Graph) error) {
+	before, err := cloneGraph(a.file.Graph)
+	if err != nil {
+		a.setError(err)
+		return
+	}
+
+	if err := fn(a.file.Graph); err != nil {
+		a.file.Graph = before
+		a.setError(err)
+		return
+	}
+
+	if err := a.file.Graph.Save(a.file.Path); err != nil {
+		a.file.Graph = before
+		a.setError(err)
+		return
+	}
+
+	a.undo = append(a.undo, before)
+	if len(a.undo) > maxUndo {
+		a.undo = a.undo[1:]
+	}
+	a.setStatus("%s", description)
+}
+
+func (a *app) undoLast() {
+	if len(a.undo) == 0 {
+		a.setStatus("Nothing to undo")
+		return
+	}
+
+	previous := a.undo[len(a.undo)-1]
+	if err := previous.Save(a.file.Path); err != nil {
+		a.setError(err)
+		return
+	}
+	a.undo = a.undo[:len(a.undo)-1]
+	a.file.Graph = previous
+	a.nodeIndex = clamp(a.nodeIndex, 0, len(previous.Nodes)-1)
+	a.edgeIndex = 0
+	a.focus = focusNodes
+	a.setStatus("Undone (%d more)", len(a.undo))
+}
+
+func (a *app) addNode(label string) {
+	if label == "" {
+		a.setStatus("Cancelled")
+		return
+	}
+	a.mutate("Added node "+label, func(g *graph.Graph) error {
+		g.Nodes = append(g.Nodes, client.Node{ID: g.NewNodeID(), Label: label})
+		return nil
+	})
+	a.nodeIndex = len(a.file.Graph.Nodes) - 1
+}
+
+func (a *app) editLabel() {
+	if nb := a.selectedNeighbor(); a.focus == focusEdges && nb != nil {
+		id := nb.Edge.ID
+		current := ""
+		if nb.Edge.Label != nil {
+			current = *nb.Edge.Label
+		}
+		a.ask("Edge label:", current, func(label string) {
+			a.mutate("Updated edge label", func(g *graph.Graph) error {
+				e := g.Edge(id)
+				e.Label = nil
+				if label != "" {
+					e.Label = &label
+				}
+				return nil
+			})
+		})
+		return
+	}
+
+	n := a.selectedNode()
+	if n == nil {
+		return
+	}
+	id := n.ID
+	a.ask("Node label:", n.Label, func(label string) {
+		if label == "" {
+			a.setStatus("Cancelled")
+			return
+		}
+		a.mutate("Renamed node to "+label, func(g *graph.Graph) error {
+			g.Node(id).Label = label
+			return nil
+		})
+	})
+}
+
+// editMarkup opens the markup of the selected node or edge in $VISUAL or
+// $EDITOR, falling back to vi.
+func (a *app) editMarkup() {
+	var current *string
+	var apply func(g *graph.Graph, markup *string)
+
+	if nb := a.selectedNeighbor(); a.focus == focusEdges && nb != nil {
+		id := nb.Edge.ID
+		current = nb.Edge.Markup
+		apply = func(g *graph.Graph, markup *string) { g.Edge(id).Markup = markup }
+	} else if n := a.selectedNode(); n != nil {
+		id := n.ID
+		current = n.Markup
+		apply = func(g *graph.Graph, markup *string) { g.Node(id).Markup = markup }
+	} else {
+		return
+	}
+
+	tmp, err := ioutil.TempFile("", "tribal-markup-*.md")
+	if err != nil {
+		a.setError(err)
+		return
+	}
+	defer os.Remove(tmp.Name())
+	if current != nil {
+		tmp.WriteString(*current)
+	}
+	tmp.Close()
+
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+
+	a.term.suspend()
+	parts := strings.Fields(editor)
+	cmd := exec.Command(parts[0], append(parts[1:], tmp.Name())...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	runErr := cmd.Run()
+	if err := a.term.resume(); err != nil {
+		a.setError(err)
+		a.quit = true
+		return
+	}
+	if runErr != nil {
+		a.setError(fmt.Errorf("editor failed: %w", runErr))
+		return
+	}
+
+	data, err := ioutil.ReadFile(tmp.Name())
+	if err != nil {
+		a.setError(err)
+		return
+	}
+
+	markup := strings.TrimRight(string(data), "\n")
+	if current != nil && *current == markup || current == nil && markup == "" {
+		a.setStatus("Markup unchanged")
+		return
+	}
+
+	a.mutate("Updated markup", func(g *graph.Graph) error {
+		if markup == "" {
+			apply(g, nil)
+		} else {
+			apply(g, &markup)
+		}
+		return nil
+	})
+}
+
+func (a *app) deleteSelected() {
+	if nb := a.selectedNeighbor(); a.focus == focusEdges && nb != nil {
+		id := nb.Edge.ID
+		a.confirm("Delete edge to "+nb.Node.Label+"?", func() {
+			a.mutate("Deleted edge", func(g *graph.Graph) error {
+				g.Edges = removeEdges(g.Edges, func(e client.Edge) bool { return e.ID == id })
+				return nil
+			})
+			a.edgeIndex = 0
+			if a.selectedNeighbor() == nil {
+				a.focus = focusNodes
+			}
+		})
+		return
+	}
+
+	n := a.selectedNode()
+	if n == nil {
+		return
+	}
+	id, label := n.ID, n.Label
+	attached := len(a.file.Graph.Neighbors(id))
+	a.confirm(fmt.Sprintf("Delete node %s and its %d edges?", label, attached), func() {
+		a.mutate("Deleted node "+label, func(g *graph.Graph) error {
+			for i := range g.Nodes {
+				if g.Nodes[i].ID == id {
+					g.Nodes = append(g.Nodes[:i], g.Nodes[i+1:]...)
+					break
+				}
+			}
+			g.Edges = removeEdges(g.Edges, func(e client.Edge) bool { return e.Source == id || e.Target == id })
+			return nil
+		})
+	})
+}
+
+func (a *app) connect(ref string) {
+	if ref == "" {
+		a.setStatus("Cancelled")
+		return
+	}
+	source := a.selectedNode()
+	target, err := a.file.Graph.Resolve(ref)
+	if err != nil {
+		a.setError(err)
+		return
+	}
+	sourceID, targetID, targetLabel := source.ID, target.ID, target.Label
+
+	a.ask("Edge label (optional):", "", func(label string) {
+		a.prompt = &prompt{label: "Directed edge? (Y/n)", single: true, submit: func(v string) {
+			a.addEdge(sourceID, targetID, targetLabel, label, !strings.EqualFold(v, "n"))
+		}}
+	})
+}
+
+func (a *app) addEdge(source, target, targetLabel, label string, directed bool) {
+	a.mutate("Connected to "+targetLabel, func(g *graph.Graph) error {
+		e := client.Edge{ID: g.NewEdgeID(), Source: source, Target: target, Directed: directed}
+		if label != "" {
+			e.Label = &label
+		}
+		g.Edges = append(g.Edges, e)
+		return nil
+	})
+}
+
+// runSuspended runs fn with the terminal restored so its output is visible,
+// then waits for Enter before returning to the UI.
+func (a *app) runSuspended(fn func() error) error {
+	a.term.suspend()
+	err := fn()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+	}
+	fmt.Print("\nPress Enter to return to tribal tui...")
+	bufio.NewReader(os.Stdin).ReadString('\n')
+
+	if resumeErr := a.term.resume(); resumeErr != nil {
+		a.quit = true
+		return resumeErr
+	}
+	return err
+}
+
+func (a *app) stage() {
+	if a.actions.Stage == nil {
+		return
+	}
+	path := a.file.Path
+	if err := a.runSuspended(func() error { return a.actions.Stage(path) }); err != nil {
+		a.setError(err)
+		return
+	}
+	a.setStatus("Staged %s. Press C to commit.", a.file.Graph.Title)
+}
+
+func (a *app) commit(message string) {
+	if message == "" {
+		a.setStatus("Cancelled: a commit message is required")
+		return
+	}
+	if a.actions.Commit == nil {
+		return
+	}
+	if err := a.runSuspended(func() error { return a.actions.Commit(message) }); err != nil {
+		a.setError(err)
+		return
+	}
+	a.setStatus("Committed: %s", message)
+}
+
+func removeEdges(edges []client.Edge, drop func(e client.Edge) bool) []client.Edge {
+	kept := edges[:0]
+	for _, e := range edges {
+		if !drop(e) {
+			kept = append(kept, e)
+		}
+	}
+	return kept
+}
+
+func cloneGraph(g *graph.Graph) (*graph.Graph, error) {
+	data, err := json.Marshal(g)
+	if err != nil {
+		return nil, fmt.Errorf("failed to snapshot graph: %w", err)
+	}
+	var clone graph.Graph
+	if err := json.Unmarshal(data, &clone); err != nil {
+		return nil, fmt.Errorf("failed to snapshot graph: %w", err)
+	}
+	return &clone, nil
+}
+
+func clamp(v, lo, hi int) int {
+	if v > hi {
+		v = hi
+	}
+	if v < lo {
+		v = lo
+	}
+	return v
+}
@@ -0,0 +1,60 @@
+package tui
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	markdownHeading  = regexp.MustCompile(`^(#{1,6})\s+(.*)`)
+	markdownListItem = regexp.MustCompile(`^(\s*)([-*+]|\d+\.)\s+(.*)`)
+	markdownEmphasis = strings.NewReplacer("**", "", "__", "", "`", "")
+)
+
+// renderMarkdown formats node markup for the terminal: headings are
+// highlighted, list items get bullets, code blocks are dimmed and paragraphs
+// are wrapped to the given width.
+func renderMarkdown(src string, width int) []line {
+	var lines []line
+	inCode := false
+
+	for _, raw := range strings.Split(src, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(raw), "```") {
+			inCode = !inCode
+			continue
+		}
+		if inCode {
+			lines = append(lines, line{{text: "  " + raw, style: styleDim}})
+			continue
+		}
+
+		if m := markdownHeading.FindStringSubmatch(raw); m != nil {
+			for _, l := range wrap(markdownEmphasis.Replace(m[2]), width) {
+				lines = append(lines, line{{text: l, style: styleBold}})
+			}
+			continue
+		}
+
+		if m := markdownListItem.FindStringSubmatch(raw); m != nil {
+			bullet := "• "
+			if strings.HasSuffix(m[2], ".") {
+				bullet = m[2] + " "
+			}
+			indent := m[1] + strings.Repeat(" ", len([]rune(bullet)))
+			for i, l := range wrap(markdownEmphasis.Replace(m[3]), width-len([]rune(indent))) {
+				prefix := indent
+				if i == 0 {
+					prefix = m[1] + bullet
+				}
+				lines = append(lines, line{{text: prefix + l}})
+			}
+			continue
+		}
+
+		for _, l := range wrap(markdownEmphasis.Replace(raw), width) {
+			lines = append(lines, line{{text: l}})
+		}
+	}
+
+	return lines
+}
@@ -0,0 +1,280 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tribal/tribal-cli/internal/anchor"
+	"github.com/tribal/tribal-cli/internal/graph"
+)
+
+var helpText = []string{
+	"Graph list",
+	"  ↑/↓ j/k   move            Enter   open graph",
+	"  n         new graph       r       reload",
+	"  q         quit",
+	"",
+	"Graph view",
+	"  ↑/↓ j/k   move            →/Tab   focus neighbors",
+	"  ←/Esc     back            Enter   jump to neighbor",
+	"  a         add node        e       edit label",
+	"  m         edit markup     d       delete node/edge",
+	"  c         connect node    x       toggle edge direction",
+	"  u         undo            PgUp/Dn scroll details",
+	"  s         stage graph     C       commit staged graph",
+	"",
+	"Press any key to close this help.",
+}
+
+func (a *app) render() {
+	width, height := a.term.size()
+
+	var body []line
+	switch {
+	case a.showHelp:
+		body = a.renderHelp()
+	case a.view == viewGraphs:
+		body = a.renderGraphs(width, height-2)
+	default:
+		body = a.renderGraph(width, height-2)
+	}
+
+	lines := make([]line, 0, height)
+	lines = append(lines, a.header(width))
+	for i := 0; i < height-2; i++ {
+		if i < len(body) {
+			lines = append(lines, body[i])
+		} else {
+			lines = append(lines, nil)
+		}
+	}
+	lines = append(lines, a.footer(width))
+	a.term.draw(lines)
+}
+
+func (a *app) header(width int) line {
+	title := " tribal"
+	if a.view == viewGraph && a.file != nil {
+		title += " › " + a.file.Graph.Title
+	}
+	return line{{text: fit(title, width, true), style: styleReverse}}
+}
+
+func (a *app) footer(width int) line {
+	if a.prompt != nil {
+		return line{
+			{text: a.prompt.label + " ", style: styleBold},
+			{text: string(a.prompt.value) + "█"},
+		}
+	}
+	if a.status != "" {
+		style := styleBold
+		if a.statusErr {
+			style = styleError
+		}
+		return line{{text: a.status, style: style}}
+	}
+	hint := "?: help  q: quit"
+	if a.view == viewGraph {
+		hint = "?: help  a: add  e: edit  m: markup  d: delete  c: connect  u: undo  s: stage  C: commit  q: back"
+	}
+	return line{{text: fit(hint, width, false), style: styleDim}}
+}
+
+func (a *app) renderHelp() []line {
+	var lines []line
+	for _, h := range helpText {
+		style := ""
+		if h != "" && !strings.HasPrefix(h, " ") {
+			style = styleHeading
+		}
+		lines = append(lines, line{{text: " " + h, style: style}})
+	}
+	return lines
+}
+
+func (a *app) renderGraphs(width, height int) []line {
+	if len(a.files) == 0 {
+		return []line{
+			{{text: " No graphs found in " + graph.Dir()}},
+			{{text: " Press n to create one.", style: styleDim}},
+		}
+	}
+
+	var lines []line
+	start := scrollStart(a.graphIndex, len(a.files), height)
+	for i := start; i < len(a.files) && len(lines) < height; i++ {
+		g := a.files[i].Graph
+		text := fmt.Sprintf(" %-40s %4d nodes %4d edges", fit(g.Title, 40, false), len(g.Nodes), len(g.Edges))
+		if desc := g.Description(); desc != "" {
+			text += "  " + desc
+		}
+		style := ""
+		if i == a.graphIndex {
+			style = styleReverse
+			text = fit(text, width, true)
+		}
+		lines = append(lines, line{{text: text, style: style}})
+	}
+	return lines
+}
+
+// renderGraph lays out three columns: the node list, the neighbors of the
+// selected node and the selected item's details.
+func (a *app) renderGraph(width, height int) []line {
+	g := a.file.Graph
+	listWidth := clamp(width/4, 16, 40)
+	neighborWidth := clamp(width/4, 16, 40)
+	detailWidth := width - listWidth - neighborWidth - 2
+
+	nodes := a.renderNodeList(listWidth, height)
+	neighbors := a.renderNeighbors(neighborWidth, height)
+
+	var details []line
+	if n := a.selectedNode(); n != nil {
+		details = a.renderDetails(detailWidth)
+	} else {
+		details = []line{
+			{{text: "This graph has no nodes.", style: styleDim}},
+			{{text: "Press a to add one.", style: styleDim}},
+		}
+	}
+	if len(g.Nodes) > 0 {
+		maxScroll := len(details) - height
+		if maxScroll < 0 {
+			maxScroll = 0
+		}
+		a.detailScroll = clamp(a.detailScroll, 0, maxScroll)
+		details = details[a.detailScroll:]
+	}
+
+	lines := make([]line, height)
+	for row := 0; row < height; row++ {
+		var l line
+		l = append(l, column(nodes, row, listWidth)...)
+		l = append(l, segment{text: "│", style: styleDim})
+		l = append(l, column(neighbors, row, neighborWidth)...)
+		l = append(l, segment{text: "│", style: styleDim})
+		if row < len(details) {
+			l = append(l, details[row]...)
+		}
+		lines[row] = l
+	}
+	return lines
+}
+
+func (a *app) renderNodeList(width, height int) []line {
+	g := a.file.Graph
+	lines := []line{{{text: fit(fmt.Sprintf(" Nodes (%d)", len(g.Nodes)), width, true), style: styleHeading}}}
+
+	start := scrollStart(a.nodeIndex, len(g.Nodes), height-1)
+	for i := start; i < len(g.Nodes) && len(lines) < height; i++ {
+		style := ""
+		if i == a.nodeIndex {
+			style = styleBold
+			if a.focus == focusNodes {
+				style = styleReverse
+			}
+		}
+		lines = append(lines, line{{text: fit(" "+g.Nodes[i].Label, width, true), style: style}})
+	}
+	return lines
+}
+
+func (a *app) renderNeighbors(width, height int) []line {
+	n := a.selectedNode()
+	if n == nil {
+		return []line{{{text: fit(" Neighbors", width, true), style: styleHeading}}}
+	}
+
+	neighbors := a.file.Graph.Neighbors(n.ID)
+	lines := []line{{{text: fit(fmt.Sprintf(" Neighbors (%d)", len(neighbors)), width, true), style: styleHeading}}}
+
+	start := scrollStart(a.edgeIndex, len(neighbors), height-1)
+	for i := start; i < len(neighbors) && len(lines) < height; i++ {
+		nb := neighbors[i]
+		arrow := "—"
+		switch nb.Direction {
+		case graph.Outgoing:
+			arrow = "→"
+		case graph.Incoming:
+			arrow = "←"
+		}
+		text := fmt.Sprintf(" %s %s", arrow, nb.Node.Label)
+		if nb.Edge.Label != nil && *nb.Edge.Label != "" {
+			text += " (" + *nb.Edge.Label + ")"
+		}
+		style := ""
+		if a.focus == focusEdges && i == a.edgeIndex {
+			style = styleReverse
+		}
+		lines = append(lines, line{{text: fit(text, width, true), style: style}})
+	}
+	return lines
+}
+
+func (a *app) renderDetails(width int) []line {
+	g := a.file.Graph
+
+	if nb := a.selectedNeighbor(); a.focus == focusEdges && nb != nil {
+		e := nb.Edge
+		arrow := "—"
+		if e.Directed {
+			arrow = "→"
+		}
+		lines := []line{
+			{{text: fmt.Sprintf(" %s %s %s", g.NodeLabel(e.Source), arrow, g.NodeLabel(e.Target)), style: styleHeading}},
+			{{text: " " + e.ID, style: styleDim}},
+			nil,
+		}
+		if e.Markup != nil && *e.Markup != "" {
+			lines = append(lines, indent(renderMarkdown(*e.Markup, width-2))...)
+		} else {
+			lines = append(lines, line{{text: " No markup. Press m to add some.", style: styleDim}})
+		}
+		return lines
+	}
+
+	n := a.selectedNode()
+	lines := []line{
+		{{text: " " + n.Label, style: styleHeading}},
+		{{text: " " + n.ID, style: styleDim}},
+	}
+	for _, anc := range n.Anchors {
+		lines = append(lines, line{{text: " ⚓ " + anchor.String(anc), style: styleDim}})
+	}
+	lines = append(lines, nil)
+	if n.Markup != nil && *n.Markup != "" {
+		lines = append(lines, indent(renderMarkdown(*n.Markup, width-2))...)
+	} else {
+		lines = append(lines, line{{text: " No markup. Press m to add some.", style: styleDim}})
+	}
+	return lines
+}
+
+// column returns the cells of row in a fixed-width column, padding blank rows.
+func column(lines []line, row, width int) line {
+	if row < len(lines) && lines[row] != nil {
+		return lines[row]
+	}
+	return line{{text: strings.Repeat(" ", width)}}
+}
+
+func indent(lines []line) []line {
+	for i, l := range lines {
+		lines[i] = append(line{{text: " "}}, l...)
+	}
+	return lines
+}
+
+// scrollStart keeps the selected row visible in a window of height rows.
+func scrollStart(selected, total, height int) int {
+	if height <= 0 || total <= height || selected < height/2 {
+		return 0
+	}
+	start := selected - height/2
+	if start > total-height {
+		start = total - height
+	}
+	return start
+}
@@ -0,0 +1,241 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+)
+
+// Key is a decoded keypress. Special keys have an empty Rune.
+type Key struct {
+	Name string
+	Rune rune
+}
+
+const (
+	keyUp        = "up"
+	keyDown      = "down"
+	keyLeft      = "left"
+	keyRight     = "right"
+	keyEnter     = "enter"
+	keyEscape    = "esc"
+	keyBackspace = "backspace"
+	keyTab       = "tab"
+	keyCtrlC     = "ctrl-c"
+	keyPageUp    = "pgup"
+	keyPageDown  = "pgdown"
+)
+
+const (
+	styleReset     = "\033[0m"
+	styleBold      = "\033[1m"
+	styleDim       = "\033[2m"
+	styleReverse   = "\033[7m"
+	styleHeading   = "\033[1;36m"
+	styleError     = "\033[1;31m"
+	enterAltScreen = "\033[?1049h\033[?25l"
+	leaveAltScreen = "\033[?25h\033[?1049l"
+)
+
+// terminal owns the raw-mode state of stdin and draws frames to stdout.
+type terminal struct {
+	fd    int
+	state *term.State
+	// pending holds input read but not yet decoded, such as pasted text.
+	pending []byte
+}
+
+func openTerminal() (*terminal, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) || !term.IsTerminal(int(os.Stdout.Fd())) {
+		return nil, fmt.Errorf("tribal tui must be run in an interactive terminal")
+	}
+
+	t := &terminal{fd: fd}
+	if err := t.resume(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (t *terminal) resume() error {
+	state, err := term.MakeRaw(t.fd)
+	if err != nil {
+		return fmt.Errorf("failed to enter raw mode: %w", err)
+	}
+	t.state = state
+	fmt.Print(enterAltScreen)
+	return nil
+}
+
+func (t *terminal) suspend() {
+	fmt.Print(leaveAltScreen)
+	if t.state != nil {
+		term.Restore(t.fd, t.state)
+		t.state = nil
+	}
+}
+
+func (t *terminal) size() (int, int) {
+	width, height, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil || width <= 0 || height <= 0 {
+		return 80, 24
+	}
+	return width, height
+}
+
+func (t *terminal) readKey() (Key, error) {
+	if len(t.pending) == 0 {
+		buf := make([]byte, 256)
+		n, err := os.Stdin.Read(buf)
+		if err != nil {
+			return Key{}, err
+		}
+		t.pending = buf[:n]
+	}
+
+	// Escape sequences arrive in a single read; anything else is decoded
+	// one rune at a time so pasted text is not lost.
+	if t.pending[0] == 0x1b {
+		key := decodeKey(t.pending)
+		t.pending = nil
+		return key, nil
+	}
+	_, size := utf8.DecodeRune(t.pending)
+	key := decodeKey(t.pending[:size])
+	t.pending = t.pending[size:]
+	return key, nil
+}
+
+func decodeKey(b []byte) Key {
+	switch s := string(b); s {
+	case "\x1b[A", "\x1bOA":
+		return Key{Name: keyUp}
+	case "\x1b[B", "\x1bOB":
+		return Key{Name: keyDown}
+	case "\x1b[C", "\x1bOC":
+		return Key{Name: keyRight}
+	case "\x1b[D", "\x1bOD":
+		return Key{Name: keyLeft}
+	case "\x1b[5~":
+		return Key{Name: keyPageUp}
+	case "\x1b[6~":
+		return Key{Name: keyPageDown}
+	case "\r", "\n":
+		return Key{Name: keyEnter}
+	case "\x1b":
+		return Key{Name: keyEscape}
+	case "\x7f", "\x08":
+		return Key{Name: keyBackspace}
+	case "\t":
+		return Key{Name: keyTab}
+	case "\x03":
+		return Key{Name: keyCtrlC}
+	}
+
+	r, _ := utf8.DecodeRune(b)
+	if r == utf8.RuneError || r < 0x20 {
+		return Key{}
+	}
+	return Key{Rune: r}
+}
+
+// segment is a run of text drawn in one style.
+type segment struct {
+	text  string
+	style string
+}
+
+type line []segment
+
+// draw writes a full frame, truncating or padding every line to the width.
+func (t *terminal) draw(lines []line) {
+	width, height := t.size()
+
+	var b strings.Builder
+	b.WriteString("\033[H")
+	for row := 0; row < height; row++ {
+		var l line
+		if row < len(lines) {
+			l = lines[row]
+		}
+		remaining := width
+		for _, seg := range l {
+			text := fit(seg.text, remaining, false)
+			remaining -= utf8.RuneCountInString(text)
+			b.WriteString(seg.style)
+			b.WriteString(text)
+			if seg.style != "" {
+				b.WriteString(styleReset)
+			}
+		}
+		b.WriteString(strings.Repeat(" ", remaining))
+		if row < height-1 {
+			b.WriteString("\r\n")
+		}
+	}
+	fmt.Print(b.String())
+}
+
+// fit truncates s to width runes, padding with spaces when pad is set.
+func fit(s string, width int, pad bool) string {
+	if width <= 0 {
+		return ""
+	}
+	s = strings.Map(func(r rune) rune {
+		if r == '\t' || r == '\n' || r == '\r' {
+			return ' '
+		}
+		return r
+	}, s)
+
+	runes := []rune(s)
+	if len(runes) > width {
+		if width > 1 {
+			return string(runes[:width-1]) + "…"
+		}
+		return string(runes[:width])
+	}
+	if pad {
+		return s + strings.Repeat(" ", width-len(runes))
+	}
+	return s
+}
+
+// wrap breaks text into lines of at most width runes, splitting on spaces.
+func wrap(text string, width int) []string {
+	if width <= 0 {
+		return nil
+	}
+
+	var lines []string
+	for _, paragraph := range strings.Split(text, "\n") {
+		words := strings.Fields(paragraph)
+		if len(words) == 0 {
+			lines = append(lines, "")
+			continue
+		}
+		current := ""
+		for _, w := range words {
+			switch {
+			case current == "":
+				current = w
+			case utf8.RuneCountInString(current)+1+utf8.RuneCountInString(w) <= width:
+				current += " " + w
+			default:
+				lines = append(lines, current)
+				current = w
+			}
+			for utf8.RuneCountInString(current) > width {
+				runes := []rune(current)
+				lines = append(lines, string(runes[:width]))
+				current = string(runes[width:])
+			}
+		}
+		lines = append(lines, current)
+	}
+	return lines
+}